@@ -0,0 +1,441 @@
+package finder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultGeocodeTTL is how long geocoding results stay fresh
+const DefaultGeocodeTTL = 30 * 24 * time.Hour
+
+// DefaultMenuTTL is how long menu check results stay fresh
+const DefaultMenuTTL = 7 * 24 * time.Hour
+
+// DefaultStoreIDTTL is how long an OSM location's store number is trusted
+const DefaultStoreIDTTL = 90 * 24 * time.Hour
+
+// DefaultCacheDir returns the directory for the finder's on-disk caches,
+// e.g. ~/.cache/chilito on Linux
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chilito"), nil
+}
+
+// jsonStore is a key/value store kept in a single JSON file. The file is
+// read on first use and rewritten atomically after every change.
+type jsonStore[V any] struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]V
+}
+
+func newJSONStore[V any](path string) *jsonStore[V] {
+	return &jsonStore[V]{path: path}
+}
+
+// load reads the file if it has not been read yet. The caller holds s.mu.
+func (s *jsonStore[V]) load() error {
+	if s.loaded {
+		return nil
+	}
+	s.entries = make(map[string]V)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return fmt.Errorf("corrupt cache file %s: %w", s.path, err)
+	}
+	s.loaded = true
+	return nil
+}
+
+// save writes the entries to a temporary file and renames it into place.
+// The caller holds s.mu.
+func (s *jsonStore[V]) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *jsonStore[V]) get(key string) (V, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var zero V
+	if err := s.load(); err != nil {
+		return zero, false, err
+	}
+	v, ok := s.entries[key]
+	return v, ok, nil
+}
+
+func (s *jsonStore[V]) put(key string, v V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.entries[key] = v
+	return s.save()
+}
+
+// all returns a copy of every entry
+func (s *jsonStore[V]) all() (map[string]V, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	entries := make(map[string]V, len(s.entries))
+	for k, v := range s.entries {
+		entries[k] = v
+	}
+	return entries, nil
+}
+
+// deleteIf removes the entries for which remove returns true and reports
+// how many were removed
+func (s *jsonStore[V]) deleteIf(remove func(V) bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	removed := 0
+	for k, v := range s.entries {
+		if remove(v) {
+			delete(s.entries, k)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.save()
+}
+
+// clear deletes the file and forgets every entry, even if the file is corrupt
+func (s *jsonStore[V]) clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]V)
+	s.loaded = true
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// GeocodeEntry is a cached geocoding result
+type GeocodeEntry struct {
+	// Address is the address as it was first requested
+	Address   string    `json:"address"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Provider  string    `json:"provider"`
+	CachedAt  time.Time `json:"cached_at"`
+}
+
+// GeocodeCache stores geocoding results on disk, keyed by normalized
+// address, so repeated searches do not hit the geocoding providers again
+type GeocodeCache struct {
+	store *jsonStore[GeocodeEntry]
+	// TTL is how long an entry stays fresh
+	TTL time.Duration
+}
+
+// OpenGeocodeCache returns a cache backed by the JSON file at path. The file
+// is created on the first write.
+func OpenGeocodeCache(path string, ttl time.Duration) *GeocodeCache {
+	return &GeocodeCache{store: newJSONStore[GeocodeEntry](path), TTL: ttl}
+}
+
+// Path returns the cache file's location
+func (c *GeocodeCache) Path() string {
+	return c.store.path
+}
+
+// Get returns the fresh entry for address, if any
+func (c *GeocodeCache) Get(address string) (GeocodeEntry, bool, error) {
+	entry, ok, err := c.store.get(normalizeAddress(address))
+	if err != nil || !ok || c.Expired(entry) {
+		return GeocodeEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Put stores the coordinates of address as found by provider
+func (c *GeocodeCache) Put(address string, lat, lng float64, provider string) error {
+	return c.store.put(normalizeAddress(address), GeocodeEntry{
+		Address:   address,
+		Latitude:  lat,
+		Longitude: lng,
+		Provider:  provider,
+		CachedAt:  time.Now().UTC(),
+	})
+}
+
+// Entries returns every entry, fresh or not, oldest first
+func (c *GeocodeCache) Entries() ([]GeocodeEntry, error) {
+	entries, err := c.store.all()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]GeocodeEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CachedAt.Before(list[j].CachedAt) })
+	return list, nil
+}
+
+// Expired reports whether entry is older than the cache's TTL. A TTL of 0
+// never expires entries.
+func (c *GeocodeCache) Expired(entry GeocodeEntry) bool {
+	return expired(entry.CachedAt, c.TTL)
+}
+
+// Prune removes expired entries and reports how many were removed
+func (c *GeocodeCache) Prune() (int, error) {
+	return c.store.deleteIf(c.Expired)
+}
+
+// Clear removes every entry
+func (c *GeocodeCache) Clear() error {
+	return c.store.clear()
+}
+
+// MenuEntry is a cached menu check result
+type MenuEntry struct {
+	StoreID     string      `json:"store_id"`
+	Item        string      `json:"item"`
+	Status      CheckStatus `json:"status"`
+	Checker     string      `json:"checker"`
+	MatchedURL  string      `json:"matched_url,omitempty"`
+	MatchedTerm string      `json:"matched_term,omitempty"`
+	Evidence    string      `json:"evidence,omitempty"`
+	Confidence  float64     `json:"confidence,omitempty"`
+	// CheckedAt is when the check that produced the entry ran
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// MenuCache stores menu check results on disk, keyed by item and store
+// number, so repeated searches do not fetch the same menu pages again
+type MenuCache struct {
+	store *jsonStore[MenuEntry]
+	// TTL is how long an entry stays fresh
+	TTL time.Duration
+}
+
+// OpenMenuCache returns a cache backed by the JSON file at path. The file is
+// created on the first write.
+func OpenMenuCache(path string, ttl time.Duration) *MenuCache {
+	return &MenuCache{store: newJSONStore[MenuEntry](path), TTL: ttl}
+}
+
+// Path returns the cache file's location
+func (c *MenuCache) Path() string {
+	return c.store.path
+}
+
+// Get returns the fresh entry for an item at a store, if any. key
+// identifies the item and how it is matched.
+func (c *MenuCache) Get(key, storeID string) (MenuEntry, bool, error) {
+	entry, ok, err := c.store.get(menuKey(key, storeID))
+	if err != nil || !ok || c.Expired(entry) {
+		return MenuEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Put stores a check result under the item's key and the location's store
+// ID
+func (c *MenuCache) Put(key string, result CheckResult) error {
+	return c.store.put(menuKey(key, result.Location.StoreID), MenuEntry{
+		StoreID:     result.Location.StoreID,
+		Item:        result.Item,
+		Status:      result.Status,
+		Checker:     result.Checker,
+		MatchedURL:  result.MatchedURL,
+		MatchedTerm: result.MatchedTerm,
+		Evidence:    result.Evidence,
+		Confidence:  result.Confidence,
+		CheckedAt:   result.CheckedAt.UTC(),
+	})
+}
+
+// Entries returns every entry, fresh or not, oldest first
+func (c *MenuCache) Entries() ([]MenuEntry, error) {
+	entries, err := c.store.all()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]MenuEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CheckedAt.Before(list[j].CheckedAt) })
+	return list, nil
+}
+
+// menuCacheVersion is bumped when changes to menu checking make earlier
+// results stale
+const menuCacheVersion = 2
+
+// menuKey identifies an item at a store
+func menuKey(key, storeID string) string {
+	return fmt.Sprintf("v%d/%s@%s", menuCacheVersion, key, storeID)
+}
+
+// Expired reports whether entry is older than the cache's TTL. A TTL of 0
+// never expires entries.
+func (c *MenuCache) Expired(entry MenuEntry) bool {
+	return expired(entry.CheckedAt, c.TTL)
+}
+
+// Prune removes expired entries and reports how many were removed
+func (c *MenuCache) Prune() (int, error) {
+	return c.store.deleteIf(c.Expired)
+}
+
+// Clear removes every entry
+func (c *MenuCache) Clear() error {
+	return c.store.clear()
+}
+
+// StoreIDEntry records the official store number an OpenStreetMap location
+// was matched to
+type StoreIDEntry struct {
+	PlaceID string `json:"place_id"`
+	// StoreNumber is empty when no official store matched
+	StoreNumber string `json:"store_number"`
+	Address     string `json:"address"`
+	// Method is how the store number was found: "geo" or "search"
+	Method    string    `json:"method"`
+	MatchedAt time.Time `json:"matched_at"`
+}
+
+// StoreIDCache stores OSM place ID to store number mappings on disk so each
+// OSM location is matched against the official store list only once
+type StoreIDCache struct {
+	store *jsonStore[StoreIDEntry]
+	// TTL is how long an entry stays fresh
+	TTL time.Duration
+}
+
+// OpenStoreIDCache returns a cache backed by the JSON file at path. The file
+// is created on the first write.
+func OpenStoreIDCache(path string, ttl time.Duration) *StoreIDCache {
+	return &StoreIDCache{store: newJSONStore[StoreIDEntry](path), TTL: ttl}
+}
+
+// Path returns the cache file's location
+func (c *StoreIDCache) Path() string {
+	return c.store.path
+}
+
+// Get returns the fresh entry for an OSM place ID, if any
+func (c *StoreIDCache) Get(placeID string) (StoreIDEntry, bool, error) {
+	entry, ok, err := c.store.get(placeID)
+	if err != nil || !ok || c.Expired(entry) {
+		return StoreIDEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Put records the store number location was matched to, or "" for none
+func (c *StoreIDCache) Put(location TacoBellLocation, storeNumber, method string) error {
+	return c.store.put(location.PlaceID, StoreIDEntry{
+		PlaceID:     location.PlaceID,
+		StoreNumber: storeNumber,
+		Address:     location.Address,
+		Method:      method,
+		MatchedAt:   time.Now().UTC(),
+	})
+}
+
+// Entries returns every entry, fresh or not, oldest first
+func (c *StoreIDCache) Entries() ([]StoreIDEntry, error) {
+	entries, err := c.store.all()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]StoreIDEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].MatchedAt.Before(list[j].MatchedAt) })
+	return list, nil
+}
+
+// Expired reports whether entry is older than the cache's TTL. A TTL of 0
+// never expires entries.
+func (c *StoreIDCache) Expired(entry StoreIDEntry) bool {
+	return expired(entry.MatchedAt, c.TTL)
+}
+
+// Prune removes expired entries and reports how many were removed
+func (c *StoreIDCache) Prune() (int, error) {
+	return c.store.deleteIf(c.Expired)
+}
+
+// Clear removes every entry
+func (c *StoreIDCache) Clear() error {
+	return c.store.clear()
+}
+
+// result rebuilds the check result for location from the entry
+func (e MenuEntry) result(location TacoBellLocation) CheckResult {
+	return CheckResult{
+		Location:    location,
+		Status:      e.Status,
+		Item:        e.Item,
+		Checker:     e.Checker,
+		MatchedURL:  e.MatchedURL,
+		MatchedTerm: e.MatchedTerm,
+		Evidence:    e.Evidence,
+		Confidence:  e.Confidence,
+		CheckedAt:   e.CheckedAt,
+		Cached:      true,
+	}
+}
+
+// expired reports whether t is more than ttl ago. A ttl of 0 never expires.
+func expired(t time.Time, ttl time.Duration) bool {
+	return ttl > 0 && time.Since(t) > ttl
+}
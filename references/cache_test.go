@@ -0,0 +1,190 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const glenBellWay = "1 Glen Bell Way, Irvine, CA"
+
+// geocodeUpstream answers Taco Bell geocoding requests from the fixture
+func geocodeUpstream(t *testing.T) *upstream {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	return u
+}
+
+func TestGeocodeCache(t *testing.T) {
+	u := geocodeUpstream(t)
+	path := filepath.Join(t.TempDir(), "geocode.json")
+
+	// Addresses that only differ in case and punctuation share an entry,
+	// which outlives the cache and finder that wrote it
+	for i, address := range []string{glenBellWay, "1 glen bell way irvine ca", glenBellWay} {
+		cache := OpenGeocodeCache(path, DefaultGeocodeTTL)
+		f := newTestFinder(u, WithGeocoderOrder("tacobell"), WithGeocodeCache(cache))
+		lat, lng, err := f.geocodeAddress(context.Background(), address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !closeTo(lat, 33.684567) || !closeTo(lng, -117.826505) {
+			t.Errorf("search %d: geocoded to %f, %f", i, lat, lng)
+		}
+	}
+	if n := u.count("/location/"); n != 1 {
+		t.Errorf("%d geocoding requests, want 1", n)
+	}
+}
+
+func TestGeocodeCacheExpiry(t *testing.T) {
+	u := geocodeUpstream(t)
+	cache := OpenGeocodeCache(filepath.Join(t.TempDir(), "geocode.json"), time.Hour)
+	stale := GeocodeEntry{Address: glenBellWay, Latitude: 1, Longitude: 2, Provider: "tacobell", CachedAt: time.Now().Add(-2 * time.Hour)}
+	if err := cache.store.put(normalizeAddress(glenBellWay), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newTestFinder(u, WithGeocoderOrder("tacobell"), WithGeocodeCache(cache))
+	lat, _, err := f.geocodeAddress(context.Background(), glenBellWay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := u.count("/location/"); n != 1 || !closeTo(lat, 33.684567) {
+		t.Errorf("latitude %f after %d requests, want a fresh lookup", lat, n)
+	}
+	if entry, ok, _ := cache.Get(glenBellWay); !ok || !closeTo(entry.Latitude, 33.684567) {
+		t.Errorf("cache.Get = %+v, %v, want the fresh result", entry, ok)
+	}
+}
+
+func TestGeocodeCacheRefresh(t *testing.T) {
+	u := geocodeUpstream(t)
+	cache := OpenGeocodeCache(filepath.Join(t.TempDir(), "geocode.json"), DefaultGeocodeTTL)
+	if err := cache.Put(glenBellWay, 1, 2, "tacobell"); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newTestFinder(u, WithGeocoderOrder("tacobell"), WithGeocodeCache(cache), WithCacheRefresh())
+	lat, _, err := f.geocodeAddress(context.Background(), glenBellWay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := u.count("/location/"); n != 1 || !closeTo(lat, 33.684567) {
+		t.Errorf("latitude %f after %d requests, want the cache bypassed", lat, n)
+	}
+	if entry, ok, _ := cache.Get(glenBellWay); !ok || !closeTo(entry.Latitude, 33.684567) {
+		t.Errorf("cache.Get = %+v, %v, want the refreshed result", entry, ok)
+	}
+}
+
+// menuUpstream serves the burritos page, which lists the item, for every store
+func menuUpstream(t *testing.T) *upstream {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos_chilito.html"))
+	return u
+}
+
+func TestMenuCache(t *testing.T) {
+	u := menuUpstream(t)
+	cache := OpenMenuCache(filepath.Join(t.TempDir(), "menu.json"), DefaultMenuTTL)
+	store := TacoBellLocation{StoreID: "031447"}
+
+	first := newTestFinder(u, WithMenuCache(cache)).CheckMenuContext(context.Background(), store)
+	if first.Status != StatusFound || first.Cached {
+		t.Fatalf("first check = %v (cached %v), want a fresh found", first.Status, first.Cached)
+	}
+	fetched := u.count("/food/")
+
+	// The second finder cannot reach anything, so the answer must come
+	// from the cache
+	second := newTestFinder(u, WithMenuCache(cache), WithTransport(failingTransport{})).
+		CheckMenuContext(context.Background(), store)
+	if second.Status != StatusFound || !second.Cached || second.MatchedURL != first.MatchedURL {
+		t.Errorf("second check = %+v, want the cached result", second)
+	}
+	if n := u.count("/food/"); n != fetched {
+		t.Errorf("%d menu pages fetched for a cache hit", n-fetched)
+	}
+
+	entries, err := cache.store.all()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := fmt.Sprintf("v%d/%s@031447", menuCacheVersion, ChiliCheeseBurrito.key(DefaultMatchThreshold))
+	if _, ok := entries[key]; !ok || len(entries) != 1 {
+		t.Errorf("cache keys = %v, want only %q", entries, key)
+	}
+}
+
+func TestMenuCacheExpiry(t *testing.T) {
+	u := menuUpstream(t)
+	cache := OpenMenuCache(filepath.Join(t.TempDir(), "menu.json"), time.Hour)
+	stale := CheckResult{
+		Location:  TacoBellLocation{StoreID: "031447"},
+		Status:    StatusNotFound,
+		CheckedAt: time.Now().Add(-2 * time.Hour),
+	}
+	if err := cache.Put(ChiliCheeseBurrito.key(DefaultMatchThreshold), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	result := newTestFinder(u, WithMenuCache(cache)).CheckMenuContext(context.Background(), stale.Location)
+	if result.Status != StatusFound || result.Cached || u.count("/food/") == 0 {
+		t.Errorf("check = %v (cached %v) after %d requests, want a fresh check", result.Status, result.Cached, u.count("/food/"))
+	}
+}
+
+func TestMenuCacheSkipsFallbackStores(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos.html"))
+	u.handle("GET /locations/search", fixture(t, "locations_search_empty.html"))
+	cache := OpenMenuCache(filepath.Join(t.TempDir(), "menu.json"), DefaultMenuTTL)
+
+	// Without a store number the menu pages say nothing about the store
+	location := TacoBellLocation{PlaceID: "osm-node-1", StoreID: "osm-node-1", Address: "Address unknown"}
+	result := newTestFinder(u, WithMenuCache(cache)).CheckMenuContext(context.Background(), location)
+	if result.Status != StatusUnknown || !result.StoreIDFallback {
+		t.Errorf("check = %v (fallback %v), want unknown", result.Status, result.StoreIDFallback)
+	}
+	if entries, err := cache.Entries(); err != nil || len(entries) != 0 {
+		t.Errorf("cache entries = %v, %v, want none", entries, err)
+	}
+}
+
+func TestStoreIDCachePrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storeids.json")
+	cache := OpenStoreIDCache(path, time.Hour)
+	old := StoreIDEntry{PlaceID: "osm-node-1", StoreNumber: "031447", MatchedAt: time.Now().Add(-2 * time.Hour)}
+	if err := cache.store.put(old.PlaceID, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put(TacoBellLocation{PlaceID: "osm-node-2"}, "", "search"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := cache.Get("osm-node-1"); ok {
+		t.Error("Get returned an expired entry")
+	}
+	entries, err := cache.Entries()
+	if err != nil || len(entries) != 2 || entries[0].PlaceID != "osm-node-1" {
+		t.Fatalf("Entries = %+v, %v, want both, oldest first", entries, err)
+	}
+	if n, err := cache.Prune(); n != 1 || err != nil {
+		t.Errorf("Prune = %d, %v, want 1 removed", n, err)
+	}
+
+	// The remaining entry was written to disk
+	reopened := OpenStoreIDCache(path, time.Hour)
+	if entry, ok, err := reopened.Get("osm-node-2"); !ok || err != nil || entry.StoreNumber != "" || entry.Method != "search" {
+		t.Errorf("reopened Get = %+v, %v, %v, want the cached miss", entry, ok, err)
+	}
+	if err := reopened.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := OpenStoreIDCache(path, time.Hour).Entries(); len(entries) != 0 {
+		t.Errorf("entries after Clear = %+v", entries)
+	}
+}
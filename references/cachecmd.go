@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+// Cache file names inside -cache-dir
+const (
+	geocodeCacheFile = "geocode.json"
+	menuCacheFile    = "menu.json"
+	storeIDCacheFile = "storeids.json"
+)
+
+// cacheFlags locate the on-disk caches
+type cacheFlags struct {
+	dir     string
+	menuTTL time.Duration
+}
+
+// register defines the cache flags on fs
+func (c *cacheFlags) register(fs *flag.FlagSet) {
+	defaultDir, err := finder.DefaultCacheDir()
+	if err != nil {
+		defaultDir = ".chilito-cache"
+	}
+	fs.StringVar(&c.dir, "cache-dir", defaultDir, "Directory for the on-disk caches")
+	fs.DurationVar(&c.menuTTL, "menu-ttl", finder.DefaultMenuTTL, "How long a store's menu check result is reused")
+}
+
+func (c *cacheFlags) geocodeCache() *finder.GeocodeCache {
+	return finder.OpenGeocodeCache(filepath.Join(c.dir, geocodeCacheFile), finder.DefaultGeocodeTTL)
+}
+
+func (c *cacheFlags) menuCache() *finder.MenuCache {
+	return finder.OpenMenuCache(filepath.Join(c.dir, menuCacheFile), c.menuTTL)
+}
+
+func (c *cacheFlags) storeIDCache() *finder.StoreIDCache {
+	return finder.OpenStoreIDCache(filepath.Join(c.dir, storeIDCacheFile), finder.DefaultStoreIDTTL)
+}
+
+// runCache implements "chilito cache list|prune|clear"
+func runCache(args []string) {
+	fs := flag.NewFlagSet("chilito cache", flag.ExitOnError)
+	var flags cacheFlags
+	flags.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  chilito cache list|prune|clear [flags]\n\n"+
+			"  list   show every cached entry and whether it is still fresh\n"+
+			"  prune  remove expired entries\n"+
+			"  clear  remove every entry\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	action := args[0]
+	fs.Parse(args[1:])
+
+	geocodes := flags.geocodeCache()
+	menus := flags.menuCache()
+	storeIDs := flags.storeIDCache()
+
+	switch action {
+	case "list":
+		listGeocodeCache(geocodes)
+		fmt.Println()
+		listMenuCache(menus)
+		fmt.Println()
+		listStoreIDCache(storeIDs)
+	case "prune":
+		removed, err := geocodes.Prune()
+		if err != nil {
+			log.Fatalf("Error pruning %s: %v", geocodes.Path(), err)
+		}
+		fmt.Printf("Removed %d expired geocode entries\n", removed)
+		removed, err = menus.Prune()
+		if err != nil {
+			log.Fatalf("Error pruning %s: %v", menus.Path(), err)
+		}
+		fmt.Printf("Removed %d expired menu entries\n", removed)
+		removed, err = storeIDs.Prune()
+		if err != nil {
+			log.Fatalf("Error pruning %s: %v", storeIDs.Path(), err)
+		}
+		fmt.Printf("Removed %d expired store ID entries\n", removed)
+	case "clear":
+		if err := geocodes.Clear(); err != nil {
+			log.Fatalf("Error clearing %s: %v", geocodes.Path(), err)
+		}
+		if err := menus.Clear(); err != nil {
+			log.Fatalf("Error clearing %s: %v", menus.Path(), err)
+		}
+		if err := storeIDs.Clear(); err != nil {
+			log.Fatalf("Error clearing %s: %v", storeIDs.Path(), err)
+		}
+		fmt.Println("Cleared geocode, menu and store ID caches")
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+func listGeocodeCache(cache *finder.GeocodeCache) {
+	entries, err := cache.Entries()
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", cache.Path(), err)
+	}
+	fmt.Printf("Geocode cache: %s (%d entries, TTL %v)\n", cache.Path(), len(entries), cache.TTL)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tLAT\tLNG\tPROVIDER\tAGE\tSTATE")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%.6f\t%.6f\t%s\t%s\t%s\n", entry.Address, entry.Latitude, entry.Longitude,
+			entry.Provider, formatAge(time.Since(entry.CachedAt)), freshness(cache.Expired(entry)))
+	}
+	w.Flush()
+}
+
+func listMenuCache(cache *finder.MenuCache) {
+	entries, err := cache.Entries()
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", cache.Path(), err)
+	}
+	fmt.Printf("Menu cache: %s (%d entries, TTL %v)\n", cache.Path(), len(entries), cache.TTL)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STORE\tITEM\tSTATUS\tCHECKER\tAGE\tSTATE")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.StoreID, entry.Item, entry.Status, entry.Checker,
+			formatAge(time.Since(entry.CheckedAt)), freshness(cache.Expired(entry)))
+	}
+	w.Flush()
+}
+
+func listStoreIDCache(cache *finder.StoreIDCache) {
+	entries, err := cache.Entries()
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", cache.Path(), err)
+	}
+	fmt.Printf("Store ID cache: %s (%d entries, TTL %v)\n", cache.Path(), len(entries), cache.TTL)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PLACE ID\tSTORE\tMETHOD\tADDRESS\tAGE\tSTATE")
+	for _, entry := range entries {
+		storeNumber := entry.StoreNumber
+		if storeNumber == "" {
+			storeNumber = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.PlaceID, storeNumber, entry.Method, entry.Address,
+			formatAge(time.Since(entry.MatchedAt)), freshness(cache.Expired(entry)))
+	}
+	w.Flush()
+}
+
+func freshness(expired bool) string {
+	if expired {
+		return "expired"
+	}
+	return "fresh"
+}
+
+// formatAge renders d the way the output says how old a cached result is,
+// e.g. "3h ago"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+}
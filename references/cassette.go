@@ -0,0 +1,209 @@
+package finder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Query parameters that differ between otherwise identical requests, such as
+// the store API's cache buster, and are ignored when matching recordings
+var volatileParams = []string{"_"}
+
+// Query parameters whose values are secrets and never written to disk
+var secretParams = []string{"access_token"}
+
+// interaction is one recorded request and its response, stored as a JSON
+// file in a cassette directory
+type interaction struct {
+	Key        string      `json:"key"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	RecordedAt time.Time   `json:"recorded_at"`
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// cassetteKey identifies a request for matching recordings: the method and
+// the URL with volatile parameters dropped and secrets redacted
+func cassetteKey(req *http.Request) string {
+	return req.Method + " " + redactURL(req.URL)
+}
+
+// redactURL returns u without volatile parameters and with secret values
+// replaced
+func redactURL(u *url.URL) string {
+	clean := *u
+	query := clean.Query()
+	for _, name := range volatileParams {
+		query.Del(name)
+	}
+	for _, name := range secretParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// cassetteFile returns the file name of the n-th recording of key
+func cassetteFile(host, key string, n int) string {
+	sum := sha256.Sum256([]byte(key))
+	host = strings.NewReplacer(":", "_", "/", "_").Replace(host)
+	return fmt.Sprintf("%s-%s-%03d.json", host, hex.EncodeToString(sum[:8]), n)
+}
+
+// Recorder is an http.RoundTripper that sends requests through Transport and
+// saves every response in Dir, so a failing search can be replayed offline
+// with a Replayer. Requests that fail without a response are not recorded.
+type Recorder struct {
+	Dir string
+	// Transport sends the requests. If nil, a Recorder given to
+	// WithTransport uses the finder's own transport; otherwise
+	// http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+// NewRecorder returns a Recorder writing to dir
+func NewRecorder(dir string, transport http.RoundTripper) *Recorder {
+	return &Recorder{Dir: dir, Transport: transport}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	key := cassetteKey(req)
+	r.mu.Lock()
+	if r.seen == nil {
+		r.seen = make(map[string]int)
+	}
+	n := r.seen[key]
+	r.seen[key]++
+	r.mu.Unlock()
+
+	rec := interaction{
+		Key:        key,
+		Method:     req.Method,
+		URL:        redactURL(req.URL),
+		RecordedAt: time.Now().UTC(),
+		Status:     resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+	}
+	if err := r.save(cassetteFile(req.URL.Host, key, n), rec); err != nil {
+		return nil, fmt.Errorf("error recording %s: %w", rec.URL, err)
+	}
+	return resp, nil
+}
+
+// save writes one interaction to Dir
+func (r *Recorder) save(name string, rec interaction) error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.Dir, name), data, 0o644)
+}
+
+// Replayer is an http.RoundTripper that answers requests from the responses
+// saved by a Recorder without touching the network. Requests that were made
+// several times get their recorded responses in order; once those run out
+// the last one is repeated.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]interaction
+	next         map[string]int
+}
+
+// NewReplayer loads the recordings in dir
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings in %s", dir)
+	}
+
+	// File names end in the recording's sequence number
+	sort.Strings(files)
+	r := &Replayer{interactions: make(map[string][]interaction), next: make(map[string]int)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var rec interaction
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("corrupt recording %s: %w", file, err)
+		}
+		r.interactions[rec.Key] = append(r.interactions[rec.Key], rec)
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	key := cassetteKey(req)
+
+	r.mu.Lock()
+	recs := r.interactions[key]
+	n := r.next[key]
+	if n < len(recs)-1 {
+		r.next[key]++
+	}
+	r.mu.Unlock()
+
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	rec := recs[n]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
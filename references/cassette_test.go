@@ -0,0 +1,148 @@
+package finder
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos.html"))
+
+	dir := t.TempDir()
+	search := func(f *ChilitoBurritoFinder) []CheckResult {
+		t.Helper()
+		results, err := f.FindChilitoBurritosContext(context.Background(), "1 Glen Bell Way, Irvine, CA", 100000, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	recorded := search(newTestFinder(u, WithTransport(NewRecorder(dir, nil))))
+	requests := u.count("")
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := search(newTestFinder(u, WithTransport(replayer)))
+
+	if u.count("") != requests {
+		t.Errorf("replay made %d requests to the upstream", u.count("")-requests)
+	}
+	if len(replayed) != len(recorded) {
+		t.Fatalf("replay found %d stores, recording %d", len(replayed), len(recorded))
+	}
+	for i := range recorded {
+		if replayed[i].Location.StoreID != recorded[i].Location.StoreID || replayed[i].Status != recorded[i].Status {
+			t.Errorf("store %d: replayed %s %v, recorded %s %v", i, replayed[i].Location.StoreID, replayed[i].Status,
+				recorded[i].Location.StoreID, recorded[i].Status)
+		}
+	}
+}
+
+func TestReplayerSequencesRepeatedRequests(t *testing.T) {
+	u := newUpstream(t)
+	calls := 0
+	u.handle("GET /food/menu", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("menu"))
+	})
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: NewRecorder(dir, http.DefaultTransport)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(u.URL + "/food/menu?store=018678")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: replayer}
+	for _, want := range []int{http.StatusTooManyRequests, http.StatusOK, http.StatusOK} {
+		resp, err := client.Get(u.URL + "/food/menu?store=018678")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("status = %d, want %d", resp.StatusCode, want)
+		}
+	}
+
+	if _, err := client.Get(u.URL + "/food/menu?store=031447"); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("unrecorded request: err = %v", err)
+	}
+}
+
+func TestRecorderRedactsSecrets(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /geocoding/v5/mapbox.places/{query}", fixture(t, "mapbox_geocode.json"))
+
+	dir := t.TempDir()
+	geocoder := &MapboxGeocoder{
+		Client:  &http.Client{Transport: NewRecorder(dir, http.DefaultTransport)},
+		Token:   "pk.secret-token",
+		BaseURL: u.URL,
+	}
+	if _, _, err := geocoder.Geocode(context.Background(), "1 Glen Bell Way"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "pk.secret-token") {
+			t.Errorf("%s contains the access token", entry.Name())
+		}
+	}
+
+	// Recordings match regardless of the token
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geocoder.Client = &http.Client{Transport: replayer}
+	geocoder.Token = "pk.other-token"
+	if _, _, err := geocoder.Geocode(context.Background(), "1 Glen Bell Way"); err != nil {
+		t.Errorf("replay with another token: %v", err)
+	}
+}
+
+func TestRecorderUsesFinderTransport(t *testing.T) {
+	rec := NewRecorder(t.TempDir(), nil)
+	NewChilitoBurritoFinder(WithTimeouts(Timeouts{ResponseHeader: 7 * time.Second}), WithTransport(rec))
+	if transport, ok := rec.Transport.(*http.Transport); !ok || transport.ResponseHeaderTimeout != 7*time.Second {
+		t.Errorf("recorder transport = %#v, want the finder's with its timeouts", rec.Transport)
+	}
+
+	// A transport given to the recorder is kept
+	rec = NewRecorder(t.TempDir(), http.DefaultTransport)
+	NewChilitoBurritoFinder(WithTransport(rec))
+	if rec.Transport != http.DefaultTransport {
+		t.Errorf("recorder transport replaced by %#v", rec.Transport)
+	}
+}
@@ -0,0 +1,424 @@
+package finder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every tunable of the finder that used to be a literal: the
+// upstream URLs, credentials, which item to look for and how to find it on
+// menu pages, timeouts and politeness limits. It is usually loaded from a
+// YAML file with LoadConfig and applied with WithConfig.
+type Config struct {
+	Endpoints Endpoints `yaml:"endpoints"`
+	// MapboxToken is the Mapbox access token
+	MapboxToken string `yaml:"mapbox_token"`
+	// NominatimUserAgent identifies the application to Nominatim
+	NominatimUserAgent string     `yaml:"nominatim_user_agent"`
+	Menu               MenuConfig `yaml:"menu"`
+	// Item selects the item to search for: a key of Items, the name or
+	// alias of one of them, or any other menu name
+	Item string `yaml:"item"`
+	// Items are named item definitions
+	Items    map[string]Item `yaml:"items"`
+	Timeouts TimeoutsConfig  `yaml:"timeouts"`
+	Retry    RetryConfig     `yaml:"retry"`
+	// Parallelism is how many stores are checked at once
+	Parallelism int `yaml:"parallelism"`
+	// PerHostLimit caps concurrent requests to any single host
+	PerHostLimit int `yaml:"per_host_limit"`
+	// RateLimits replace DefaultRateLimits for the listed hosts
+	RateLimits map[string]RateLimitConfig `yaml:"rate_limits"`
+}
+
+// MenuConfig configures how menu pages are scraped for every item
+type MenuConfig struct {
+	// MatchThreshold is the lowest match confidence, from 0 to 1, that
+	// counts as the item being on the menu
+	MatchThreshold float64  `yaml:"match_threshold"`
+	Selectors      []string `yaml:"selectors"`
+	// Regions locate the store's menu on a page, in order of preference
+	Regions []string `yaml:"regions"`
+	// Chrome matches navigation, footers, promos and scripts to ignore
+	Chrome []string `yaml:"chrome"`
+	// UnavailablePhrases and UnavailableSelectors mark a listed item's
+	// product card as not available at the store
+	UnavailablePhrases   []string `yaml:"unavailable_phrases"`
+	UnavailableSelectors []string `yaml:"unavailable_selectors"`
+	Paths                []string `yaml:"paths"`
+	UserAgents           []string `yaml:"user_agents"`
+}
+
+// TimeoutsConfig is the file form of Timeouts
+type TimeoutsConfig struct {
+	Request        Duration `yaml:"request"`
+	Dial           Duration `yaml:"dial"`
+	TLSHandshake   Duration `yaml:"tls_handshake"`
+	ResponseHeader Duration `yaml:"response_header"`
+	IdleConn       Duration `yaml:"idle_conn"`
+}
+
+// RetryConfig is the file form of RetryPolicy
+type RetryConfig struct {
+	MaxAttempts int      `yaml:"max_attempts"`
+	BaseDelay   Duration `yaml:"base_delay"`
+	MaxDelay    Duration `yaml:"max_delay"`
+	MaxElapsed  Duration `yaml:"max_elapsed"`
+}
+
+// RateLimitConfig is the file form of RateLimit
+type RateLimitConfig struct {
+	Interval Duration `yaml:"interval"`
+	Burst    int      `yaml:"burst"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s"
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// DefaultConfig returns the built-in settings
+func DefaultConfig() Config {
+	rateLimits := make(map[string]RateLimitConfig, len(DefaultRateLimits))
+	for host, limit := range DefaultRateLimits {
+		rateLimits[host] = RateLimitConfig{Interval: Duration(limit.Interval), Burst: limit.Burst}
+	}
+	return Config{
+		Endpoints: Endpoints{
+			TacoBell:    DefaultTacoBellURL,
+			TacoBellAPI: DefaultTacoBellAPIURL,
+			Nominatim:   DefaultNominatimEndpoint,
+			Overpass:    DefaultOverpassEndpoint,
+			Mapbox:      DefaultMapboxURL,
+		},
+		MapboxToken:        DefaultMapboxToken,
+		NominatimUserAgent: DefaultNominatimUserAgent,
+		Menu: MenuConfig{
+			MatchThreshold:       DefaultMatchThreshold,
+			Selectors:            append([]string{}, DefaultMenuSelectors...),
+			Regions:              append([]string{}, DefaultMenuRegionSelectors...),
+			Chrome:               append([]string{}, DefaultChromeSelectors...),
+			UnavailablePhrases:   append([]string{}, DefaultUnavailablePhrases...),
+			UnavailableSelectors: append([]string{}, DefaultUnavailableSelectors...),
+			Paths:                append([]string{}, DefaultMenuPaths...),
+			UserAgents:           append([]string{}, DefaultScrapeUserAgents...),
+		},
+		Item:  DefaultItemKey,
+		Items: map[string]Item{DefaultItemKey: ChiliCheeseBurrito},
+		Timeouts: TimeoutsConfig{
+			Request:        Duration(DefaultTimeouts.Request),
+			Dial:           Duration(DefaultTimeouts.Dial),
+			TLSHandshake:   Duration(DefaultTimeouts.TLSHandshake),
+			ResponseHeader: Duration(DefaultTimeouts.ResponseHeader),
+			IdleConn:       Duration(DefaultTimeouts.IdleConn),
+		},
+		Retry: RetryConfig{
+			MaxAttempts: DefaultRetryPolicy.MaxAttempts,
+			BaseDelay:   Duration(DefaultRetryPolicy.BaseDelay),
+			MaxDelay:    Duration(DefaultRetryPolicy.MaxDelay),
+			MaxElapsed:  Duration(DefaultRetryPolicy.MaxElapsed),
+		},
+		Parallelism:  DefaultParallelism,
+		PerHostLimit: DefaultPerHostLimit,
+		RateLimits:   rateLimits,
+	}
+}
+
+// withDefaults fills the settings a hand-built Config leaves unset from
+// DefaultConfig. A zero PerHostLimit and missing RateLimits are kept, since
+// they mean no limit and the default limits respectively.
+func (c Config) withDefaults() Config {
+	def := DefaultConfig()
+	c.Endpoints = def.Endpoints.merge(c.Endpoints)
+	c.MapboxToken = stringOr(c.MapboxToken, def.MapboxToken)
+	c.NominatimUserAgent = stringOr(c.NominatimUserAgent, def.NominatimUserAgent)
+	if c.Menu.MatchThreshold == 0 {
+		c.Menu.MatchThreshold = def.Menu.MatchThreshold
+	}
+	c.Menu.Selectors = orDefault(c.Menu.Selectors, def.Menu.Selectors)
+	c.Menu.Regions = orDefault(c.Menu.Regions, def.Menu.Regions)
+	c.Menu.Chrome = orDefault(c.Menu.Chrome, def.Menu.Chrome)
+	c.Menu.UnavailablePhrases = orDefault(c.Menu.UnavailablePhrases, def.Menu.UnavailablePhrases)
+	c.Menu.UnavailableSelectors = orDefault(c.Menu.UnavailableSelectors, def.Menu.UnavailableSelectors)
+	c.Menu.Paths = orDefault(c.Menu.Paths, def.Menu.Paths)
+	c.Menu.UserAgents = orDefault(c.Menu.UserAgents, def.Menu.UserAgents)
+	if c.Item == "" {
+		c.Item = def.Item
+	}
+	if c.Items == nil {
+		c.Items = def.Items
+	}
+	if c.Retry == (RetryConfig{}) {
+		c.Retry = def.Retry
+	} else if c.Retry.MaxAttempts == 0 {
+		c.Retry.MaxAttempts = def.Retry.MaxAttempts
+	}
+	if c.Parallelism < 1 {
+		c.Parallelism = def.Parallelism
+	}
+	return c
+}
+
+// DefaultConfigPath returns where the config file is looked for when no
+// path is given, e.g. ~/.config/chilito/config.yaml on Linux
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chilito", "config.yaml"), nil
+}
+
+// LoadConfig reads the YAML file at path over DefaultConfig, applies the
+// CHILITO_* environment overrides and validates the result. Settings missing
+// from the file keep their defaults. An empty path skips the file.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		if path != "" {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Environment variables that override the config file
+var envStrings = map[string]func(*Config) *string{
+	"CHILITO_ITEM":                 func(c *Config) *string { return &c.Item },
+	"CHILITO_MAPBOX_TOKEN":         func(c *Config) *string { return &c.MapboxToken },
+	"CHILITO_NOMINATIM_USER_AGENT": func(c *Config) *string { return &c.NominatimUserAgent },
+	"CHILITO_TACOBELL_URL":         func(c *Config) *string { return &c.Endpoints.TacoBell },
+	"CHILITO_TACOBELL_API_URL":     func(c *Config) *string { return &c.Endpoints.TacoBellAPI },
+	"CHILITO_NOMINATIM_URL":        func(c *Config) *string { return &c.Endpoints.Nominatim },
+	"CHILITO_OVERPASS_URL":         func(c *Config) *string { return &c.Endpoints.Overpass },
+	"CHILITO_MAPBOX_URL":           func(c *Config) *string { return &c.Endpoints.Mapbox },
+}
+
+// applyEnv applies the CHILITO_* environment variables found by lookup
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for name, field := range envStrings {
+		if v, ok := lookup(name); ok {
+			*field(c) = v
+		}
+	}
+	if v, ok := lookup("CHILITO_TIMEOUT"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("CHILITO_TIMEOUT: %w", err)
+		}
+		c.Timeouts.Request = Duration(d)
+	}
+	if v, ok := lookup("CHILITO_PARALLELISM"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("CHILITO_PARALLELISM: %w", err)
+		}
+		c.Parallelism = n
+	}
+	return nil
+}
+
+// Validate reports every invalid setting
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	for name, value := range map[string]string{
+		"endpoints.tacobell":     c.Endpoints.TacoBell,
+		"endpoints.tacobell_api": c.Endpoints.TacoBellAPI,
+		"endpoints.nominatim":    c.Endpoints.Nominatim,
+		"endpoints.overpass":     c.Endpoints.Overpass,
+		"endpoints.mapbox":       c.Endpoints.Mapbox,
+	} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"%s: %q is not an http(s) URL", name, value)
+	}
+
+	checkSelectors := func(name string, selectors []string) {
+		for _, selector := range selectors {
+			_, err := cascadia.Compile(selector)
+			check(err == nil, "%s: %q: %v", name, selector, err)
+		}
+	}
+	checkPaths := func(name string, paths []string) {
+		for _, path := range paths {
+			check(strings.HasPrefix(path, "/"), "%s: %q must start with /", name, path)
+		}
+	}
+	check(c.Menu.MatchThreshold > 0 && c.Menu.MatchThreshold <= 1, "menu.match_threshold: must be above 0 and at most 1")
+	checkSelectors("menu.selectors", c.Menu.Selectors)
+	checkSelectors("menu.regions", c.Menu.Regions)
+	checkSelectors("menu.chrome", c.Menu.Chrome)
+	checkSelectors("menu.unavailable_selectors", c.Menu.UnavailableSelectors)
+	checkPaths("menu.paths", c.Menu.Paths)
+
+	check(strings.TrimSpace(c.Item) != "", "item: must not be empty")
+	for key, item := range c.Items {
+		check(strings.TrimSpace(item.Name) != "", "items.%s.name: must not be empty", key)
+		checkSelectors("items."+key+".selectors", item.Selectors)
+		checkPaths("items."+key+".paths", item.Paths)
+	}
+
+	for name, d := range map[string]Duration{
+		"timeouts.request":         c.Timeouts.Request,
+		"timeouts.dial":            c.Timeouts.Dial,
+		"timeouts.tls_handshake":   c.Timeouts.TLSHandshake,
+		"timeouts.response_header": c.Timeouts.ResponseHeader,
+		"timeouts.idle_conn":       c.Timeouts.IdleConn,
+		"retry.base_delay":         c.Retry.BaseDelay,
+		"retry.max_delay":          c.Retry.MaxDelay,
+		"retry.max_elapsed":        c.Retry.MaxElapsed,
+	} {
+		check(d >= 0, "%s: must not be negative", name)
+	}
+	check(c.Retry.MaxAttempts >= 1, "retry.max_attempts: must be at least 1")
+	check(c.Parallelism >= 1, "parallelism: must be at least 1")
+	check(c.PerHostLimit >= 0, "per_host_limit: must not be negative")
+	for host, limit := range c.RateLimits {
+		check(limit.Interval >= 0 && limit.Burst >= 0, "rate_limits.%s: interval and burst must not be negative", host)
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of c that is safe to print
+func (c Config) Redacted() Config {
+	if c.MapboxToken != "" && c.MapboxToken != DefaultMapboxToken {
+		c.MapboxToken = "REDACTED"
+	}
+	return c
+}
+
+// timeouts converts the file form to Timeouts
+func (c Config) timeouts() Timeouts {
+	return Timeouts{
+		Request:        time.Duration(c.Timeouts.Request),
+		Dial:           time.Duration(c.Timeouts.Dial),
+		TLSHandshake:   time.Duration(c.Timeouts.TLSHandshake),
+		ResponseHeader: time.Duration(c.Timeouts.ResponseHeader),
+		IdleConn:       time.Duration(c.Timeouts.IdleConn),
+	}.withDefaults()
+}
+
+// retryPolicy converts the file form to a RetryPolicy
+func (c Config) retryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: c.Retry.MaxAttempts,
+		BaseDelay:   time.Duration(c.Retry.BaseDelay),
+		MaxDelay:    time.Duration(c.Retry.MaxDelay),
+		MaxElapsed:  time.Duration(c.Retry.MaxElapsed),
+	}
+}
+
+// geocoders returns the built-in geocoders in their default order
+func (c Config) geocoders(client *http.Client) []Geocoder {
+	return []Geocoder{
+		&TacoBellGeocoder{Client: client, BaseURL: c.Endpoints.TacoBellAPI},
+		&MapboxGeocoder{Client: client, BaseURL: c.Endpoints.Mapbox, Token: c.MapboxToken},
+		&NominatimGeocoder{Client: client, Endpoint: c.Endpoints.Nominatim, UserAgent: c.NominatimUserAgent},
+	}
+}
+
+// locators returns the built-in store locators in their default order
+func (c Config) locators(client *http.Client) []StoreLocator {
+	return []StoreLocator{
+		&TacoBellLocator{Client: client, BaseURL: c.Endpoints.TacoBell},
+		&OverpassLocator{Client: client, Endpoint: c.Endpoints.Overpass},
+	}
+}
+
+// SelectedItem returns the item named by c.Item. A key of Items, or the
+// name or an alias of one of them, selects that definition, ignoring case;
+// any other name is searched for as is.
+func (c Config) SelectedItem() Item {
+	return c.LookupItem(c.Item)
+}
+
+// LookupItem is like SelectedItem for the given name
+func (c Config) LookupItem(name string) Item {
+	want := strings.ToLower(strings.TrimSpace(name))
+	if item, ok := c.Items[want]; ok {
+		return item
+	}
+	for key, item := range c.Items {
+		if strings.ToLower(key) == want {
+			return item
+		}
+		for _, term := range item.SearchTerms() {
+			if term == want {
+				return item
+			}
+		}
+	}
+	return Item{Name: strings.TrimSpace(name)}
+}
+
+// menuChecker returns the standard menu checker for item
+func (c Config) menuChecker(client *http.Client, item Item) MenuChecker {
+	return AnyOf(c.scraper(client, item), NewKnownLocationsChecker(item.KnownStores...))
+}
+
+// scraper returns the menu page scraper for item
+func (c Config) scraper(client *http.Client, item Item) *ScrapingMenuChecker {
+	return &ScrapingMenuChecker{
+		Client:               client,
+		BaseURL:              c.Endpoints.TacoBell,
+		SearchTerms:          item.SearchTerms(),
+		Exclude:              item.ExcludeTerms(),
+		Threshold:            c.Menu.MatchThreshold,
+		Selectors:            orDefault(item.Selectors, c.Menu.Selectors),
+		Paths:                orDefault(item.Paths, c.Menu.Paths),
+		Regions:              c.Menu.Regions,
+		Chrome:               c.Menu.Chrome,
+		UnavailablePhrases:   c.Menu.UnavailablePhrases,
+		UnavailableSelectors: c.Menu.UnavailableSelectors,
+		UserAgents:           c.Menu.UserAgents,
+	}
+}
@@ -0,0 +1,172 @@
+package finder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigOverridesDefaults(t *testing.T) {
+	path := writeConfig(t, `
+endpoints:
+  nominatim: http://localhost:8088/search
+menu:
+  paths: [/food/menu]
+item: mexican pizza
+items:
+  pizza:
+    name: Mexican Pizza
+    exclude: [mexican pizza sauce]
+timeouts:
+  request: 5s
+parallelism: 2
+rate_limits:
+  nominatim.openstreetmap.org:
+    interval: 3s
+    burst: 1
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoints.Nominatim != "http://localhost:8088/search" || cfg.Endpoints.TacoBell != DefaultTacoBellURL {
+		t.Errorf("Endpoints = %+v", cfg.Endpoints)
+	}
+	if len(cfg.Menu.Paths) != 1 || len(cfg.Menu.Selectors) != len(DefaultMenuSelectors) {
+		t.Errorf("Menu = %+v", cfg.Menu)
+	}
+	if item := cfg.SelectedItem(); item.Name != "Mexican Pizza" || len(item.Exclude) != 1 {
+		t.Errorf("SelectedItem = %+v", item)
+	}
+	if _, ok := cfg.Items[DefaultItemKey]; !ok {
+		t.Errorf("Items = %v, want the built-in items kept", cfg.Items)
+	}
+	if cfg.timeouts().Request != 5*time.Second || cfg.timeouts().Dial != DefaultTimeouts.Dial {
+		t.Errorf("timeouts = %+v", cfg.timeouts())
+	}
+	if cfg.Parallelism != 2 || cfg.PerHostLimit != DefaultPerHostLimit {
+		t.Errorf("Parallelism = %d, PerHostLimit = %d", cfg.Parallelism, cfg.PerHostLimit)
+	}
+	if got := cfg.RateLimits["nominatim.openstreetmap.org"]; time.Duration(got.Interval) != 3*time.Second {
+		t.Errorf("nominatim rate limit = %+v", got)
+	}
+}
+
+func TestLoadConfigEnvironment(t *testing.T) {
+	t.Setenv("CHILITO_MAPBOX_TOKEN", "pk.secret")
+	t.Setenv("CHILITO_TACOBELL_URL", "http://localhost:9000")
+	t.Setenv("CHILITO_TIMEOUT", "7s")
+
+	cfg, err := LoadConfig(writeConfig(t, "mapbox_token: pk.from-file\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MapboxToken != "pk.secret" || cfg.Endpoints.TacoBell != "http://localhost:9000" ||
+		time.Duration(cfg.Timeouts.Request) != 7*time.Second {
+		t.Errorf("environment not applied: %+v", cfg)
+	}
+	if cfg.Redacted().MapboxToken == "pk.secret" {
+		t.Error("Redacted kept the Mapbox token")
+	}
+}
+
+func TestLoadConfigRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "parallelizm: 4\n", "parallelizm"},
+		{"bad duration", "timeouts:\n  request: soon\n", "soon"},
+		{"bad URL", "endpoints:\n  overpass: overpass-api.de/api\n", "endpoints.overpass"},
+		{"bad selector", "menu:\n  selectors: ['div[']\n", "menu.selectors"},
+		{"unnamed item", "items:\n  pizza:\n    aliases: [pizza]\n", "items.pizza.name"},
+		{"threshold above 1", "menu:\n  match_threshold: 1.5\n", "menu.match_threshold"},
+		{"zero parallelism", "parallelism: 0\n", "parallelism"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConfigItem(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos.html"))
+
+	// The fixture lists the Beefy 5-Layer Burrito but not the Chilito
+	cfg := DefaultConfig()
+	cfg.Endpoints = u.endpoints()
+	cfg.Items["beefy"] = Item{Name: "Beefy 5-Layer Burrito", Aliases: []string{"beefy 5-layer"}}
+	cfg.Item = "beefy 5-layer"
+	f := newTestFinder(u, WithConfig(cfg))
+	result := f.CheckMenuContext(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusFound || result.Item != "Beefy 5-Layer Burrito" {
+		t.Errorf("CheckMenu = %v for %q, want found for the configured item", result.Status, result.Item)
+	}
+}
+
+func TestWithConfigOrder(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos_chilito.html"))
+
+	// The config points nowhere useful; the options given before it win
+	cfg := DefaultConfig()
+	cfg.Endpoints.TacoBell = "http://127.0.0.1:1"
+	cfg.Parallelism = 1
+	f := newTestFinder(u, WithParallelism(3), WithConfig(cfg))
+	if f.parallelism != 3 {
+		t.Errorf("parallelism = %d, want 3 from WithParallelism", f.parallelism)
+	}
+	result := f.CheckMenuContext(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusFound {
+		t.Errorf("CheckMenu = %v (%v), want found on the WithEndpoints upstream", result.Status, result.Err)
+	}
+}
+
+func TestWithConfigFillsDefaults(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos_chilito.html"))
+
+	// A hand-built config that only sets one endpoint
+	f := NewChilitoBurritoFinder(WithConfig(Config{Endpoints: Endpoints{TacoBell: u.URL}}))
+	if f.parallelism != DefaultParallelism || f.Item().Name != ChiliCheeseBurrito.Name {
+		t.Errorf("parallelism = %d, item = %q, want the defaults", f.parallelism, f.Item().Name)
+	}
+	if g, ok := f.geocoders[0].(*TacoBellGeocoder); !ok || g.BaseURL != DefaultTacoBellAPIURL {
+		t.Errorf("first geocoder = %#v, want Taco Bell's at the default URL", f.geocoders[0])
+	}
+	result := f.CheckMenuContext(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusFound {
+		t.Errorf("CheckMenu = %v (%v), want found with the default menu settings", result.Status, result.Err)
+	}
+}
+
+func TestWithEndpointsMergesFields(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Endpoints.Nominatim = "http://nominatim.test/search"
+	f := NewChilitoBurritoFinder(WithConfig(cfg), WithEndpoints(Endpoints{TacoBell: "http://tacobell.test"}))
+
+	if l, ok := f.locators[0].(*TacoBellLocator); !ok || l.BaseURL != "http://tacobell.test" {
+		t.Errorf("first locator = %#v, want Taco Bell's at the WithEndpoints URL", f.locators[0])
+	}
+	if g, ok := f.geocoders[2].(*NominatimGeocoder); !ok || g.Endpoint != cfg.Endpoints.Nominatim {
+		t.Errorf("third geocoder = %#v, want Nominatim at the configured URL", f.geocoders[2])
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+// loadConfig loads the -config file, or the default config file if it exists
+// and no path was given, and returns the path that was read
+func loadConfig(path string) (finder.Config, string, error) {
+	if path == "" {
+		defaultPath, err := finder.DefaultConfigPath()
+		if err == nil {
+			if _, err := os.Stat(defaultPath); err == nil {
+				path = defaultPath
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return finder.Config{}, "", err
+			}
+		}
+	}
+	cfg, err := finder.LoadConfig(path)
+	return cfg, path, err
+}
+
+// runConfig implements "chilito config show"
+func runConfig(args []string) {
+	flags := flag.NewFlagSet("chilito config", flag.ExitOnError)
+	var path string
+	flags.StringVar(&path, "config", "", "Config file (default: the user config directory's chilito/config.yaml, if present)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage:\n  chilito config show [flags]\n\n"+
+			"  show  print the effective configuration: defaults, then the config file,\n"+
+			"        then CHILITO_* environment variables\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "show" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	flags.Parse(args[1:])
+
+	cfg, loaded, err := loadConfig(path)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if loaded != "" {
+		fmt.Printf("# loaded from %s\n", loaded)
+	} else {
+		fmt.Println("# built-in defaults")
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		log.Fatalf("Error writing config: %v", err)
+	}
+	enc.Close()
+}
@@ -0,0 +1,48 @@
+package finder
+
+// Base URLs of the upstream services used by the built-in providers
+const (
+	DefaultTacoBellURL    = "https://www.tacobell.com"
+	DefaultTacoBellAPIURL = "https://api.tacobell.com"
+	DefaultMapboxURL      = "https://api.mapbox.com"
+)
+
+// Endpoints overrides the upstream URLs the built-in providers talk to, for
+// example to point them at self-hosted mirrors or local test servers. Empty
+// fields keep the defaults.
+type Endpoints struct {
+	// TacoBell serves the store API, the store search page and the menu
+	// pages (defaults to DefaultTacoBellURL)
+	TacoBell string `yaml:"tacobell"`
+	// TacoBellAPI serves the geocoding API (defaults to DefaultTacoBellAPIURL)
+	TacoBellAPI string `yaml:"tacobell_api"`
+	// Nominatim is the search URL (defaults to DefaultNominatimEndpoint)
+	Nominatim string `yaml:"nominatim"`
+	// Overpass is the interpreter URL (defaults to DefaultOverpassEndpoint)
+	Overpass string `yaml:"overpass"`
+	// Mapbox serves the geocoding API (defaults to DefaultMapboxURL)
+	Mapbox string `yaml:"mapbox"`
+}
+
+// merge returns e with every non-empty field of over replacing its own
+func (e Endpoints) merge(over Endpoints) Endpoints {
+	e.TacoBell = stringOr(over.TacoBell, e.TacoBell)
+	e.TacoBellAPI = stringOr(over.TacoBellAPI, e.TacoBellAPI)
+	e.Nominatim = stringOr(over.Nominatim, e.Nominatim)
+	e.Overpass = stringOr(over.Overpass, e.Overpass)
+	e.Mapbox = stringOr(over.Mapbox, e.Mapbox)
+	return e
+}
+
+// tacoBell returns the Taco Bell website URL
+func (e Endpoints) tacoBell() string {
+	return stringOr(e.TacoBell, DefaultTacoBellURL)
+}
+
+// stringOr returns value, or fallback if value is empty
+func stringOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,205 @@
+package finder
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// DefaultChromeSelectors match site-wide page parts that can mention any
+// item regardless of what the store sells: scripts, navigation, headers,
+// footers and promo banners. They are removed before matching, except that
+// a product card's own header and the like are kept.
+var DefaultChromeSelectors = []string{
+	"script",
+	"style",
+	"noscript",
+	"template",
+	"iframe",
+	"nav",
+	"header",
+	"footer",
+	"aside",
+	"[role=navigation]",
+	"[role=banner]",
+	"[role=contentinfo]",
+	"[role=complementary]",
+	"[aria-hidden=true]",
+	".promo",
+	".promo-banner",
+	".banner",
+}
+
+// DefaultMenuRegionSelectors locate the store-specific menu on a page, in
+// order of preference. The whole body is used if none matches.
+var DefaultMenuRegionSelectors = []string{
+	"[data-store-menu]",
+	"main",
+	"[role=main]",
+	"#main-content",
+}
+
+// menuRegion removes the page-level chrome from doc and returns the part of
+// the page that holds the store's menu
+func menuRegion(doc *goquery.Document, regions, chrome []string) *goquery.Selection {
+	if len(chrome) > 0 {
+		doc.Find(strings.Join(chrome, ", ")).FilterFunction(func(i int, s *goquery.Selection) bool {
+			return invisibleElements[goquery.NodeName(s)] || s.ParentsFiltered(productCardMarkers).Length() == 0
+		}).Remove()
+	}
+	for _, selector := range regions {
+		if region := doc.Find(selector).First(); region.Length() > 0 {
+			return region
+		}
+	}
+	return doc.Find("body")
+}
+
+// menuEntry is a candidate item name and the element it was read from
+type menuEntry struct {
+	name string
+	elem *goquery.Selection
+}
+
+// menuEntries extracts the candidate item names from a menu region: the
+// text of the elements matching selectors or, if there are none, every
+// block of visible text
+func menuEntries(region *goquery.Selection, selectors string) []menuEntry {
+	var entries []menuEntry
+	region.Find(selectors).Each(func(i int, s *goquery.Selection) {
+		if text := strings.TrimSpace(s.Text()); text != "" {
+			entries = append(entries, menuEntry{name: text, elem: s})
+		}
+	})
+	if len(entries) > 0 {
+		return entries
+	}
+	for _, node := range region.Nodes {
+		entries = appendTextBlocks(entries, region, node)
+	}
+	return entries
+}
+
+// Elements whose text is never shown as part of the menu
+var invisibleElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+}
+
+// appendTextBlocks appends the text directly inside n and each of its
+// descendants, one entry per element. region is the selection n is in.
+func appendTextBlocks(blocks []menuEntry, region *goquery.Selection, n *html.Node) []menuEntry {
+	var own strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			own.WriteString(c.Data)
+			own.WriteByte(' ')
+		case html.ElementNode:
+			if !invisibleElements[c.Data] {
+				blocks = appendTextBlocks(blocks, region, c)
+			}
+		}
+	}
+	if text := strings.Join(strings.Fields(own.String()), " "); text != "" {
+		elem := region.FindNodes(n)
+		if elem.Length() == 0 {
+			elem = region // n is the region itself
+		}
+		blocks = append(blocks, menuEntry{name: text, elem: elem})
+	}
+	return blocks
+}
+
+// DefaultUnavailablePhrases in a listed item's product card mean the store
+// does not currently sell it
+var DefaultUnavailablePhrases = []string{
+	"not available at this location",
+	"not available at this store",
+	"not available at this restaurant",
+	"currently unavailable",
+	"unavailable",
+	"sold out",
+	"out of stock",
+}
+
+// DefaultUnavailableSelectors match the parts of a product card that mark
+// the item as unavailable, such as a disabled add-to-cart button
+var DefaultUnavailableSelectors = []string{
+	"button[disabled]",
+	"[aria-disabled=true]",
+	"[data-available=false]",
+	".sold-out",
+	".unavailable",
+	".is-unavailable",
+	".disabled",
+}
+
+// productCardSelectors match the element that holds everything about one
+// product: its name, price and order button
+const productCardSelectors = "[data-product-code], [data-product-id], .product-card, .menu-item, article, li"
+
+// productCardMarkers match only elements that are certainly product cards.
+// Chrome inside them, such as a card's header, is kept; chrome inside a
+// generic list item or article, such as a promo in a carousel, is not.
+const productCardMarkers = "[data-product-code], [data-product-id], .product-card"
+
+// productCard returns the element around entries[i] that holds everything
+// about its product: the nearest product card or, failing that, the entry's
+// parent. The result is empty if that element holds another entry as well,
+// since its badges and buttons could then belong to either product. Entries
+// that are only an availability note or a price don't count.
+func productCard(entries []menuEntry, i int, phrases []string) *goquery.Selection {
+	elem := entries[i].elem
+	card := elem.Closest(productCardSelectors)
+	if card.Length() == 0 {
+		card = elem.Parent()
+	}
+
+	phrases = lowerUnique(phrases)
+	for j, other := range entries {
+		if j == i || other.elem.Length() == 0 || isLabel(other.name, phrases) {
+			continue
+		}
+		if card.IsSelection(other.elem) || card.Contains(other.elem.Get(0)) {
+			return card.Slice(0, 0)
+		}
+	}
+	return card
+}
+
+// isLabel reports whether text is an availability note or a price rather
+// than a product name
+func isLabel(text string, phrases []string) bool {
+	text = strings.ToLower(text)
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return pricePattern.MatchString(text) && len(strings.Fields(text)) <= 2
+}
+
+// unavailableSignal describes why a product card marks its item as
+// unavailable, or returns "" if it does not or card is empty
+func unavailableSignal(card *goquery.Selection, phrases, selectors []string) string {
+	if card.Length() == 0 {
+		return ""
+	}
+	for _, selector := range selectors {
+		if card.Is(selector) || card.Find(selector).Length() > 0 {
+			return "product card matches " + selector
+		}
+	}
+	text := strings.ToLower(strings.Join(strings.Fields(card.Text()), " "))
+	for _, phrase := range lowerUnique(phrases) {
+		if strings.Contains(text, phrase) {
+			return "product card says " + strconv.Quote(phrase)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestMenuEntries(t *testing.T) {
+	page := `<html><head><title>Chili Cheese Burrito</title></head><body>
+		<script>var items = ["Chili Cheese Burrito"];</script>
+		<p>Try the <b>Chilito</b> today</p>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without any product elements every visible text block is a name
+	var names []string
+	for _, entry := range menuEntries(doc.Find("body"), ".product-name") {
+		names = append(names, entry.name)
+	}
+	want := []string{"Chilito", "Try the today"}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Errorf("menuEntries = %q, want %q", names, want)
+	}
+}
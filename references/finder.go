@@ -1,12 +1,11 @@
 package finder
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"math"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -15,8 +14,6 @@ import (
 	"strings"
 	"time"
 
-	"net/http/cookiejar"
-
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -28,6 +25,16 @@ type TacoBellLocation struct {
 	Distance    float64 // in kilometers
 	PhoneNumber string
 	StoreID     string
+	Latitude    float64
+	Longitude   float64
+	// Source is the name of the StoreLocator that found this location, e.g.
+	// "tacobell" for official store numbers or "overpass" for OSM placeholders
+	Source string
+}
+
+// hasCoordinates reports whether the location's coordinates are known
+func (l TacoBellLocation) hasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
 }
 
 // PlaceDetails stores additional details about a place
@@ -35,406 +42,377 @@ type PlaceDetails struct {
 	PhoneNumber string
 }
 
-// ChilitoBurritoFinder manages searching for the Chilito Burrito
+// ChilitoBurritoFinder manages searching for a menu item, the Chilito Burrito
+// unless configured otherwise
 type ChilitoBurritoFinder struct {
-	client *http.Client
+	client        *http.Client
+	item          Item
+	geocoders     []Geocoder
+	locators      []StoreLocator
+	mergeLocators bool
+	checker       MenuChecker
+	parallelism   int
+	logger        *slog.Logger
+	geocodeCache  *GeocodeCache
+	menuCache     *MenuCache
+	storeIDCache  *StoreIDCache
+	refreshCache  bool
+	// storeMatcher finds the official stores near an OSM location
+	storeMatcher StoreLocator
+	// websiteURL is the base URL of the store search page
+	websiteURL string
+	// menuScraper reads whole menus for FetchMenu
+	menuScraper *ScrapingMenuChecker
+	// itemKey identifies item and how it is matched in the menu cache
+	itemKey string
 }
 
 // NewChilitoBurritoFinder creates a new finder instance
-func NewChilitoBurritoFinder() *ChilitoBurritoFinder {
-	return &ChilitoBurritoFinder{
-		client: &http.Client{Timeout: 20 * time.Second},
-	}
-}
-
-// FindNearestChilitoBurrito finds the nearest Taco Bell with a Chili Cheese Burrito
-func (f *ChilitoBurritoFinder) FindNearestChilitoBurrito(address string, radius int) (*TacoBellLocation, error) {
-	// Get coordinates for the address
-	lat, lng, err := f.geocodeAddress(address)
-	if err != nil {
-		return nil, fmt.Errorf("geocoding error: %w", err)
+func NewChilitoBurritoFinder(opts ...Option) *ChilitoBurritoFinder {
+	// A config from WithConfig is applied first so that the other options
+	// override it wherever they appear; finding it takes a pass of its own
+	var probe finderOptions
+	for _, opt := range opts {
+		opt(&probe)
 	}
 
-	// Find Taco Bell locations near these coordinates
-	locations, err := f.findTacoBellLocations(lat, lng, radius)
-	if err != nil {
-		return nil, fmt.Errorf("location search error: %w", err)
+	o := finderOptions{parallelism: DefaultParallelism, perHostLimit: DefaultPerHostLimit,
+		retry: DefaultRetryPolicy, timeouts: DefaultTimeouts, config: DefaultConfig()}
+	if probe.fromConfig != nil {
+		o.applyConfig(*probe.fromConfig)
 	}
-
-	if len(locations) == 0 {
-		return nil, errors.New("no Taco Bell locations found in the specified radius")
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	// Sort locations by distance
-	sort.Slice(locations, func(i, j int) bool {
-		return locations[i].Distance < locations[j].Distance
-	})
-
-	// Check each location for the Chilito/Chili Cheese Burrito
-	for _, location := range locations {
-		fmt.Printf("Checking menu at %s (%.2f km away)...\n", location.Name, location.Distance)
-
-		// Get the store ID from the Taco Bell website
-		storeID, err := f.getStoreID(location)
-		if err != nil {
-			fmt.Printf("Error getting store ID for %s: %v\n", location.Name, err)
-			continue
-		}
-		location.StoreID = storeID
-
-		// Check if this store has the Chilito
-		hasChilito, err := f.checkForChilitoBurrito(location)
-		if err != nil {
-			fmt.Printf("Error checking menu at %s: %v\n", location.Name, err)
-			continue
-		}
-
-		if hasChilito {
-			return &location, nil
-		}
+	f := &ChilitoBurritoFinder{client: o.newClient()}
+	o.build(f, f.client)
+	return f
+}
 
-		fmt.Printf("Chilito Burrito not found at %s\n", location.Name)
+// Geocoders returns the names of the configured geocoders in the order they are tried
+func (f *ChilitoBurritoFinder) Geocoders() []string {
+	names := make([]string, len(f.geocoders))
+	for i, g := range f.geocoders {
+		names[i] = g.Name()
 	}
-
-	return nil, nil
+	return names
 }
 
-// geocodeAddress converts an address to coordinates
-func (f *ChilitoBurritoFinder) geocodeAddress(address string) (float64, float64, error) {
-	// Try Taco Bell geocoding first, then fall back to other methods if needed
-	methods := []func(string) (float64, float64, error){
-		f.tacoBellGeocode,
-		f.mapboxGeocode,
-		f.openStreetMapGeocode,
-	}
+// Item returns the item the finder looks for
+func (f *ChilitoBurritoFinder) Item() Item {
+	return f.item
+}
 
-	var lastErr error
-	for _, method := range methods {
-		lat, lng, err := method(address)
-		if err == nil {
-			return lat, lng, nil
-		}
-		lastErr = err
-		fmt.Printf("Geocoding method failed: %v\n", err)
+// StoreLocators returns the names of the configured store locators in order
+func (f *ChilitoBurritoFinder) StoreLocators() []string {
+	names := make([]string, len(f.locators))
+	for i, l := range f.locators {
+		names[i] = l.Name()
 	}
+	return names
+}
 
-	return 0, 0, fmt.Errorf("all geocoding methods failed - last error: %w", lastErr)
+// SearchInterruptedError is returned when a search's context is canceled or
+// times out before the search completes. It carries the progress made so far.
+type SearchInterruptedError struct {
+	// Located is every restaurant found within the radius, nearest first
+	Located []TacoBellLocation
+	// Checked is the stores whose menus were checked without a match
+	Checked []TacoBellLocation
+	// Found is the stores confirmed to sell the item before the search
+	// was interrupted, including ones whose nearer neighbors were still
+	// being checked
+	Found []TacoBellLocation
+	Err   error
 }
 
-// tacoBellGeocode attempts to geocode using Taco Bell's official API
-func (f *ChilitoBurritoFinder) tacoBellGeocode(address string) (float64, float64, error) {
-	fmt.Printf("Using Taco Bell's official geocoding API for: %s\n", address)
+func (e *SearchInterruptedError) Error() string {
+	return fmt.Sprintf("search interrupted after checking %d of %d stores: %v",
+		len(e.Checked)+len(e.Found), len(e.Located), e.Err)
+}
 
-	// Use Taco Bell's official geocoding API
-	encodedAddress := url.QueryEscape(address)
-	requestURL := fmt.Sprintf("https://api.tacobell.com/location/v1/%s", encodedAddress)
+func (e *SearchInterruptedError) Unwrap() error {
+	return e.Err
+}
 
-	// Create request with headers
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return 0, 0, fmt.Errorf("error creating request: %w", err)
+// add records the outcome of one store check
+func (e *SearchInterruptedError) add(result CheckResult) {
+	switch result.Status {
+	case StatusFound:
+		e.Found = append(e.Found, result.Location)
+	case StatusNotFound, StatusNotAvailable:
+		e.Checked = append(e.Checked, result.Location)
 	}
+}
 
-	// Set headers to mimic browser behavior
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Referer", "https://www.tacobell.com/")
+// FindNearestChilitoBurrito finds the nearest Taco Bell that sells the item
+func (f *ChilitoBurritoFinder) FindNearestChilitoBurrito(address string, radius int) (*TacoBellLocation, error) {
+	return f.FindNearestChilitoBurritoContext(context.Background(), address, radius)
+}
 
-	// Send the request
-	resp, err := f.client.Do(req)
+// FindNearestChilitoBurritoContext is like FindNearestChilitoBurrito but
+// aborts in-flight requests when ctx is done. If that happens after stores
+// were located, the error is a *SearchInterruptedError.
+func (f *ChilitoBurritoFinder) FindNearestChilitoBurritoContext(ctx context.Context, address string, radius int) (*TacoBellLocation, error) {
+	results, err := f.FindChilitoBurritosContext(ctx, address, radius, 1)
 	if err != nil {
-		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("taco bell API returned status code %d", resp.StatusCode)
-	}
-
-	// Parse the JSON response
-	var result struct {
-		Geometry struct {
-			Lat float64 `json:"lat"`
-			Lng float64 `json:"lng"`
-		} `json:"geometry"`
-		Success bool `json:"success"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, 0, fmt.Errorf("error parsing JSON response: %w", err)
+		return nil, err
 	}
 
-	if !result.Success {
-		return 0, 0, fmt.Errorf("taco Bell API geocoding was not successful")
+	for _, result := range results {
+		if result.Found() {
+			return &result.Location, nil
+		}
 	}
-
-	fmt.Printf("Taco Bell API geocoding successful: %f, %f\n", result.Geometry.Lat, result.Geometry.Lng)
-	return result.Geometry.Lat, result.Geometry.Lng, nil
+	return nil, nil
 }
 
-// openStreetMapGeocode attempts to geocode using OSM's Nominatim API
-func (f *ChilitoBurritoFinder) openStreetMapGeocode(address string) (float64, float64, error) {
-	endpoint := "https://nominatim.openstreetmap.org/search"
-
-	params := url.Values{}
-	params.Add("q", address)
-	params.Add("format", "json")
-	params.Add("limit", "1")
-	params.Add("addressdetails", "1")
-
-	fmt.Printf("Trying OpenStreetMap geocoding for: %s\n", address)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	req, err := http.NewRequest("GET", endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return 0, 0, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Set required User-Agent for Nominatim
-	req.Header.Set("User-Agent", "ChilitoBurritoFinder/1.0 (github.com/yourusername/chilito)")
+// FindChilitoBurritos checks the Taco Bells within radius meters of address
+// nearest first and returns a result for every store examined, sorted by
+// distance. It stops once limit stores are confirmed to sell the item; a
+// limit of 0 checks every store in the radius.
+func (f *ChilitoBurritoFinder) FindChilitoBurritos(address string, radius, limit int) ([]CheckResult, error) {
+	return f.FindChilitoBurritosContext(context.Background(), address, radius, limit)
+}
 
-	resp, err := client.Do(req)
+// FindChilitoBurritosContext is like FindChilitoBurritos but aborts in-flight
+// requests when ctx is done. If that happens after stores were located, the
+// checks completed so far are returned, still sorted by distance, with a
+// *SearchInterruptedError.
+func (f *ChilitoBurritoFinder) FindChilitoBurritosContext(ctx context.Context, address string, radius, limit int) ([]CheckResult, error) {
+	ctx = withLogger(ctx, f.logger)
+	locations, err := f.LocateStoresContext(ctx, address, radius)
 	if err != nil {
-		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+		return nil, err
 	}
 
-	// Print the entire response for debugging
-	body, err := io.ReadAll(resp.Body)
+	// Check the locations concurrently; outcomes arrive nearest first, so
+	// the results stay sorted by distance
+	var results []CheckResult
+	confirmed := 0
+	undelivered, err := f.checkLocations(ctx, locations, func(result CheckResult) bool {
+		results = append(results, result)
+		logger := f.logger.With(
+			"store_id", result.Location.StoreID,
+			"name", result.Location.Name,
+			"distance_km", result.Location.Distance,
+			"checker", result.Checker,
+			"status", result.Status,
+			"duration", result.Duration,
+		)
+		switch result.Status {
+		case StatusError:
+			logger.Warn("menu check failed", "error", result.Err)
+		case StatusFound:
+			logger.Info("item found", "item", f.item.Name, "url", result.MatchedURL, "term", result.MatchedTerm)
+			confirmed++
+			return limit <= 0 || confirmed < limit
+		default:
+			logger.Info("item not found", "item", f.item.Name)
+		}
+		return true
+	})
 	if err != nil {
-		return 0, 0, fmt.Errorf("error reading response body: %w", err)
+		interrupted := &SearchInterruptedError{Located: locations, Err: err}
+		for _, result := range results {
+			interrupted.add(result)
+		}
+		for _, result := range undelivered {
+			interrupted.add(result)
+		}
+		return append(results, undelivered...), interrupted
 	}
 
-	fmt.Printf("OpenStreetMap response: %s\n", string(body))
+	return results, nil
+}
 
-	var results []struct {
-		Lat string `json:"lat"`
-		Lon string `json:"lon"`
-	}
+// LocateStores geocodes address and returns the Taco Bells within radius
+// meters of it, nearest first, without checking their menus
+func (f *ChilitoBurritoFinder) LocateStores(address string, radius int) ([]TacoBellLocation, error) {
+	return f.LocateStoresContext(context.Background(), address, radius)
+}
 
-	if err := json.Unmarshal(body, &results); err != nil {
-		return 0, 0, fmt.Errorf("error parsing JSON response: %w", err)
-	}
+// LocateStoresContext is like LocateStores but aborts when ctx is done
+func (f *ChilitoBurritoFinder) LocateStoresContext(ctx context.Context, address string, radius int) ([]TacoBellLocation, error) {
+	ctx = withLogger(ctx, f.logger)
 
-	if len(results) == 0 {
-		return 0, 0, errors.New("no geocoding results returned")
+	// Get coordinates for the address
+	lat, lng, err := f.geocodeAddress(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("geocoding error: %w", err)
 	}
 
-	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	// Find Taco Bell locations near these coordinates
+	locations, err := f.findTacoBellLocations(ctx, lat, lng, radius)
 	if err != nil {
-		return 0, 0, fmt.Errorf("invalid latitude: %w", err)
+		return nil, fmt.Errorf("location search error: %w", err)
 	}
 
-	lng, err := strconv.ParseFloat(results[0].Lon, 64)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid longitude: %w", err)
+	if len(locations) == 0 {
+		return nil, errors.New("no Taco Bell locations found in the specified radius")
 	}
 
-	fmt.Printf("OpenStreetMap geocoding successful: %f, %f\n", lat, lng)
-	return lat, lng, nil
+	// Sort locations by distance
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].Distance < locations[j].Distance
+	})
+	return locations, nil
 }
 
-// mapboxGeocode attempts to geocode using Mapbox API (as another alternative)
-func (f *ChilitoBurritoFinder) mapboxGeocode(address string) (float64, float64, error) {
-	// Using a placeholder token - in production you'd use your own token
-	token := "MAPBOX_TOKEN_PLACEHOLDER" // Changed from actual token to a placeholder
-	encodedAddress := url.QueryEscape(address)
-
-	endpoint := fmt.Sprintf("https://api.mapbox.com/geocoding/v5/mapbox.places/%s.json?access_token=%s",
-		encodedAddress, token)
+// CheckMenu resolves the store ID of location if needed and asks the
+// configured MenuChecker whether the store sells the item
+func (f *ChilitoBurritoFinder) CheckMenu(location TacoBellLocation) CheckResult {
+	return f.CheckMenuContext(context.Background(), location)
+}
 
-	fmt.Printf("Trying Mapbox geocoding for: %s\n", address)
+// CheckMenuContext is like CheckMenu but aborts when ctx is done
+func (f *ChilitoBurritoFinder) CheckMenuContext(ctx context.Context, location TacoBellLocation) CheckResult {
+	ctx = withLogger(ctx, f.logger)
+	ctx, attempts := withAttemptCounter(ctx)
+	start := time.Now()
+	result := f.checkMenu(ctx, location)
+	if !result.Cached {
+		result.CheckedAt = start
+	}
+	result.Duration = time.Since(start)
+	result.Attempts = int(attempts.Load())
+	result.Item = f.item.Name
+	return result
+}
 
-	resp, err := http.Get(endpoint)
+// checkMenu implements CheckMenuContext
+func (f *ChilitoBurritoFinder) checkMenu(ctx context.Context, location TacoBellLocation) CheckResult {
+	// Get the store ID from the Taco Bell website
+	storeID, err := f.getStoreID(ctx, location)
 	if err != nil {
-		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
+		return errorResult(location, "", fmt.Errorf("error getting store ID: %w", err))
 	}
-	defer resp.Body.Close()
+	location.StoreID = storeID
+	fallback := !isStoreNumber(storeID)
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+	// Only official store numbers identify a menu well enough to cache it
+	useCache := f.menuCache != nil && !fallback
+	if useCache && !f.refreshCache {
+		entry, ok, err := f.menuCache.Get(f.itemKey, storeID)
+		if err != nil {
+			f.logger.Warn("menu cache unavailable", "path", f.menuCache.Path(), "error", err)
+			useCache = false
+		} else if ok {
+			f.logger.Debug("menu check from cache", "store", storeID, "status", entry.Status, "checked_at", entry.CheckedAt)
+			return entry.result(location)
+		}
 	}
 
-	var result struct {
-		Features []struct {
-			Center []float64 `json:"center"` // [longitude, latitude]
-		} `json:"features"`
-	}
+	start := time.Now()
+	result := f.checker.CheckMenu(ctx, location)
+	result.Location = location
+	result.StoreIDFallback = fallback
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, 0, fmt.Errorf("error parsing JSON response: %w", err)
+	// Without a real store number the menu pages are not store-specific,
+	// so a miss says nothing about this store
+	if fallback && (result.Status == StatusNotFound || result.Status == StatusNotAvailable) {
+		result.Status = StatusUnknown
 	}
 
-	if len(result.Features) == 0 {
-		return 0, 0, errors.New("no geocoding results returned")
+	// Errors and non-answers are worth retrying on the next run
+	if useCache && (result.Status == StatusFound || result.Status == StatusNotFound || result.Status == StatusNotAvailable) {
+		result.CheckedAt = start
+		result.Item = f.item.Name
+		if err := f.menuCache.Put(f.itemKey, result); err != nil {
+			f.logger.Warn("could not update menu cache", "path", f.menuCache.Path(), "error", err)
+		}
 	}
-
-	// Mapbox returns [lng, lat] whereas most APIs use [lat, lng]
-	lng := result.Features[0].Center[0]
-	lat := result.Features[0].Center[1]
-
-	fmt.Printf("Mapbox geocoding successful: %f, %f\n", lat, lng)
-	return lat, lng, nil
+	return result
 }
 
-// findTacoBellLocations finds Taco Bell restaurants near coordinates
-func (f *ChilitoBurritoFinder) findTacoBellLocations(lat, lng float64, radius int) ([]TacoBellLocation, error) {
-	fmt.Printf("Searching for Taco Bell locations near coordinates: %f, %f (radius: %d meters)\n",
-		lat, lng, radius)
+// FetchMenu returns every item on the menu pages of the store with the
+// given official store number, with category, price and availability
+func (f *ChilitoBurritoFinder) FetchMenu(storeID string) ([]MenuItem, error) {
+	return f.FetchMenuContext(context.Background(), storeID)
+}
 
-	// Use Taco Bell website API to search for locations
-	locations, err := f.tacoBellWebsiteSearch(lat, lng, radius)
-	if err != nil {
-		fmt.Printf("Taco Bell official API search error: %v\n", err)
-		// Fall back to OpenStreetMap if Taco Bell API fails
-		locations, err = f.openStreetMapSearch(lat, lng, radius)
-		if err != nil {
-			return nil, fmt.Errorf("all search methods failed: %w", err)
-		}
+// FetchMenuContext is like FetchMenu but aborts when ctx is done
+func (f *ChilitoBurritoFinder) FetchMenuContext(ctx context.Context, storeID string) ([]MenuItem, error) {
+	if !isStoreNumber(storeID) {
+		return nil, fmt.Errorf("invalid store number %q", storeID)
 	}
-
-	fmt.Printf("Total Taco Bell locations found: %d\n", len(locations))
-	return locations, nil
+	return f.menuScraper.FetchMenu(withLogger(ctx, f.logger), storeID)
 }
 
-// openStreetMapSearch searches for Taco Bell locations using OSM Overpass API
-func (f *ChilitoBurritoFinder) openStreetMapSearch(lat, lng float64, radius int) ([]TacoBellLocation, error) {
-	// Convert radius from meters to degrees (approximate)
-	radiusDegrees := float64(radius) / 111000.0 // 1 degree is roughly 111 km
-
-	// Build Overpass query to find Taco Bell locations
-	bbox := fmt.Sprintf("%.6f,%.6f,%.6f,%.6f",
-		lng-radiusDegrees, lat-radiusDegrees,
-		lng+radiusDegrees, lat+radiusDegrees)
-
-	query := fmt.Sprintf(`[out:json];
-		(
-		  node["amenity"="fast_food"]["name"~"Taco Bell",i](%s);
-		  way["amenity"="fast_food"]["name"~"Taco Bell",i](%s);
-		  relation["amenity"="fast_food"]["name"~"Taco Bell",i](%s);
-		);
-		out center;`, bbox, bbox, bbox)
-
-	// URL encode the query
-	encoded := url.QueryEscape(query)
-	requestURL := "https://overpass-api.de/api/interpreter?data=" + encoded
-
-	fmt.Println("Making OpenStreetMap Overpass API request...")
-
-	client := http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return nil, err
+// isStoreNumber reports whether id looks like an official Taco Bell store
+// number rather than a placeholder such as "osm-node-123"
+func isStoreNumber(id string) bool {
+	if id == "" {
+		return false
 	}
-	defer resp.Body.Close()
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("overpass API returned status %d", resp.StatusCode)
-	}
-
-	var result struct {
-		Elements []struct {
-			Type string `json:"type"`
-			ID   int64  `json:"id"`
-			Tags struct {
-				Name        string `json:"name"`
-				Housenumber string `json:"addr:housenumber"`
-				Street      string `json:"addr:street"`
-				City        string `json:"addr:city"`
-				State       string `json:"addr:state"`
-				Postcode    string `json:"addr:postcode"`
-				Phone       string `json:"phone"`
-			} `json:"tags"`
-			Lat    float64 `json:"lat"`
-			Lon    float64 `json:"lon"`
-			Center struct {
-				Lat float64 `json:"lat"`
-				Lon float64 `json:"lon"`
-			} `json:"center"`
-		} `json:"elements"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+// geocodeAddress converts an address to coordinates
+func (f *ChilitoBurritoFinder) geocodeAddress(ctx context.Context, address string) (float64, float64, error) {
+	if len(f.geocoders) == 0 {
+		return 0, 0, errors.New("no geocoders configured")
 	}
 
-	var locations []TacoBellLocation
-	for _, element := range result.Elements {
-		// Get coordinates based on element type
-		nodeLat, nodeLng := element.Lat, element.Lon
-		if element.Type != "node" {
-			// For ways and relations, use center
-			nodeLat, nodeLng = element.Center.Lat, element.Center.Lon
+	if f.geocodeCache != nil && !f.refreshCache {
+		entry, ok, err := f.geocodeCache.Get(address)
+		if err != nil {
+			f.logger.Warn("geocode cache unavailable", "path", f.geocodeCache.Path(), "error", err)
+		} else if ok {
+			f.logger.Info("geocoded address from cache", "provider", entry.Provider,
+				"lat", entry.Latitude, "lng", entry.Longitude, "cached_at", entry.CachedAt)
+			return entry.Latitude, entry.Longitude, nil
 		}
+	}
 
-		// Build address from components
-		address := ""
-		if element.Tags.Housenumber != "" && element.Tags.Street != "" {
-			address = element.Tags.Housenumber + " " + element.Tags.Street
-		}
-		if element.Tags.City != "" {
-			if address != "" {
-				address += ", "
-			}
-			address += element.Tags.City
-		}
-		if element.Tags.State != "" {
-			if address != "" {
-				address += ", "
-			}
-			address += element.Tags.State
-		}
-		if element.Tags.Postcode != "" {
-			if address != "" {
-				address += " "
+	// Try each geocoder in order, falling back to the next one on failure
+	var lastErr error
+	for _, geocoder := range f.geocoders {
+		lat, lng, err := geocoder.Geocode(ctx, address)
+		if err == nil {
+			f.logger.Info("geocoded address", "provider", geocoder.Name(), "lat", lat, "lng", lng)
+			if f.geocodeCache != nil {
+				if err := f.geocodeCache.Put(address, lat, lng, geocoder.Name()); err != nil {
+					f.logger.Warn("could not update geocode cache", "path", f.geocodeCache.Path(), "error", err)
+				}
 			}
-			address += element.Tags.Postcode
+			return lat, lng, nil
 		}
-
-		if address == "" {
-			address = "Address unknown"
+		if ctx.Err() != nil {
+			return 0, 0, ctx.Err()
 		}
+		lastErr = fmt.Errorf("%s: %w", geocoder.Name(), err)
+		f.logger.Warn("geocoding failed", "provider", geocoder.Name(), "error", err)
+	}
 
-		// Calculate distance
-		distance := haversineDistance(lat, lng, nodeLat, nodeLng)
-
-		// Build unique ID for OSM elements
-		placeID := fmt.Sprintf("osm-%s-%d", element.Type, element.ID)
-
-		locations = append(locations, TacoBellLocation{
-			PlaceID:     placeID,
-			Name:        element.Tags.Name,
-			Address:     address,
-			Distance:    distance,
-			PhoneNumber: element.Tags.Phone,
-			StoreID:     placeID, // Use the OSM ID as a fallback store ID
-		})
+	return 0, 0, fmt.Errorf("all geocoding methods failed - last error: %w", lastErr)
+}
 
-		fmt.Printf("Found Taco Bell (OSM): %s at %s (%.2f km)\n",
-			element.Tags.Name, address, distance)
-	}
+var (
+	punctuationPattern = regexp.MustCompile(`[^\w\s]`)
+	whitespacePattern  = regexp.MustCompile(`\s+`)
+)
 
-	return locations, nil
+// normalizeAddress lowercases an address, removes punctuation and
+// standardizes whitespace
+func normalizeAddress(s string) string {
+	s = strings.ToLower(s)
+	s = punctuationPattern.ReplaceAllString(s, " ")
+	s = whitespacePattern.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
 }
 
 // similarAddresses checks if two addresses are similar enough to be considered the same location
 func similarAddresses(addr1, addr2 string) bool {
 	// Normalize both addresses: lowercase, remove punctuation, standardize whitespace
-	normalize := func(s string) string {
-		s = strings.ToLower(s)
-		s = regexp.MustCompile(`[^\w\s]`).ReplaceAllString(s, " ")
-		s = regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")
-		s = strings.TrimSpace(s)
-		return s
-	}
-
-	norm1 := normalize(addr1)
-	norm2 := normalize(addr2)
+	norm1 := normalizeAddress(addr1)
+	norm2 := normalizeAddress(addr2)
 
 	// Direct match after normalization
 	if norm1 == norm2 {
@@ -475,7 +453,7 @@ func similarAddresses(addr1, addr2 string) bool {
 }
 
 // getStoreID gets the Taco Bell store ID which is needed for menu checking
-func (f *ChilitoBurritoFinder) getStoreID(location TacoBellLocation) (string, error) {
+func (f *ChilitoBurritoFinder) getStoreID(ctx context.Context, location TacoBellLocation) (string, error) {
 	// If we already have a store ID from the official API, use it
 	if location.StoreID != "" && len(location.StoreID) > 0 && location.StoreID != location.PlaceID {
 		return location.StoreID, nil
@@ -486,17 +464,80 @@ func (f *ChilitoBurritoFinder) getStoreID(location TacoBellLocation) (string, er
 		return location.PlaceID, nil
 	}
 
-	// Format the address for URL query
-	formattedAddress := url.QueryEscape(location.Address)
-	locationURL := fmt.Sprintf("https://www.tacobell.com/locations/search?q=%s", formattedAddress)
+	useCache := f.storeIDCache != nil && location.PlaceID != ""
+	if useCache && !f.refreshCache {
+		entry, ok, err := f.storeIDCache.Get(location.PlaceID)
+		if err != nil {
+			f.logger.Warn("store ID cache unavailable", "path", f.storeIDCache.Path(), "error", err)
+			useCache = false
+		} else if ok {
+			if entry.StoreNumber == "" {
+				return location.PlaceID, nil
+			}
+			return entry.StoreNumber, nil
+		}
+	}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 15 * time.Second,
+	storeNumber, method, err := f.resolveStoreNumber(ctx, location)
+	if err != nil {
+		return "", err
+	}
+
+	// Misses are cached too, so unmatched OSM locations are not looked up
+	// on every run. A failed lookup returned an error above, so a miss here
+	// means the store really has no number to be found.
+	if useCache {
+		if err := f.storeIDCache.Put(location, storeNumber, method); err != nil {
+			f.logger.Warn("could not update store ID cache", "path", f.storeIDCache.Path(), "error", err)
+		}
+	}
+
+	// If we still don't have a store ID, use the Place ID as a fallback
+	if storeNumber == "" {
+		loggerFrom(ctx).Warn("could not find store ID, using fallback",
+			"name", location.Name, "place_id", location.PlaceID)
+		return location.PlaceID, nil
 	}
+	return storeNumber, nil
+}
+
+// resolveStoreNumber finds the official store number of a location that
+// came without one. It returns "" when none could be found, along with the
+// method that found it: "geo" or "search". If a lookup failed, an error is
+// returned instead of "", since the store may well have a number.
+func (f *ChilitoBurritoFinder) resolveStoreNumber(ctx context.Context, location TacoBellLocation) (string, string, error) {
+	// Pairing with the official store list by position is the most reliable
+	var matchErr error
+	if location.hasCoordinates() && f.storeMatcher != nil {
+		storeNumber, err := matchOfficialStore(ctx, f.storeMatcher, location)
+		if err != nil {
+			loggerFrom(ctx).Debug("store matching failed, searching instead", "place_id", location.PlaceID, "error", err)
+			matchErr = fmt.Errorf("matching official stores: %w", err)
+		} else if storeNumber != "" {
+			loggerFrom(ctx).Debug("matched official store", "place_id", location.PlaceID, "store", storeNumber)
+			return storeNumber, "geo", nil
+		}
+	}
+
+	storeNumber, err := f.searchStoreNumber(ctx, location)
+	switch {
+	case err != nil:
+		return "", "search", errors.Join(matchErr, fmt.Errorf("searching stores: %w", err))
+	case storeNumber == "" && matchErr != nil:
+		return "", "geo", matchErr
+	}
+	return storeNumber, "search", nil
+}
+
+// searchStoreNumber looks a location's address up on the Taco Bell store
+// search page
+func (f *ChilitoBurritoFinder) searchStoreNumber(ctx context.Context, location TacoBellLocation) (string, error) {
+	// Format the address for URL query
+	formattedAddress := url.QueryEscape(location.Address)
+	locationURL := fmt.Sprintf("%s/locations/search?q=%s", f.websiteURL, formattedAddress)
 
 	// Create a request with headers to mimic a browser
-	req, err := http.NewRequest("GET", locationURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", locationURL, nil)
 	if err != nil {
 		return "", err
 	}
@@ -506,8 +547,8 @@ func (f *ChilitoBurritoFinder) getStoreID(location TacoBellLocation) (string, er
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 
-	// Execute request
-	resp, err := client.Do(req)
+	// Execute request through the shared client so per-host limits apply
+	resp, err := f.client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -569,155 +610,12 @@ func (f *ChilitoBurritoFinder) getStoreID(location TacoBellLocation) (string, er
 		})
 	}
 
-	// If we still don't have a store ID, use the Place ID as a fallback
 	if storeID == "" {
-		fmt.Printf("Warning: Could not find store ID for %s, using fallback\n", location.Name)
-		storeID = location.PlaceID
+		loggerFrom(ctx).Debug("store search found no store number", "place_id", location.PlaceID, "url", locationURL)
 	}
-
 	return storeID, nil
 }
 
-// checkForChilitoBurrito checks if a location has the Chili Cheese Burrito
-func (f *ChilitoBurritoFinder) checkForChilitoBurrito(location TacoBellLocation) (bool, error) {
-	fmt.Printf("Checking menu at Taco Bell %s (%s)...\n", location.StoreID, location.Name)
-
-	// Terms that indicate the Chilito/Chili Cheese Burrito
-	searchTerms := []string{
-		"chili cheese burrito",
-		"chilito burrito",
-		"chilito",
-		"chili burrito",
-		"ccb", // sometimes used as abbreviation
-	}
-
-	// Create client with appropriate timeout and cookies
-	jar, _ := cookiejar.New(nil)
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        10,
-			IdleConnTimeout:     30 * time.Second,
-			DisableCompression:  false,
-			TLSHandshakeTimeout: 10 * time.Second,
-		},
-		Jar: jar,
-	}
-
-	// Use a browser-like User-Agent
-	userAgents := []string{
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:89.0) Gecko/20100101 Firefox/89.0",
-	}
-
-	// URLs to check for the Chilito
-	urls := []string{
-		fmt.Sprintf("https://www.tacobell.com/food/menu?store=%s", location.StoreID),
-		fmt.Sprintf("https://www.tacobell.com/food/burritos?store=%s", location.StoreID),
-		fmt.Sprintf("https://www.tacobell.com/food/specialties?store=%s", location.StoreID),
-		fmt.Sprintf("https://www.tacobell.com/food/specialty?store=%s", location.StoreID),
-	}
-
-	// Try different approaches for each URL
-	for _, menuURL := range urls {
-		// Try multiple attempts with exponential backoff
-		var htmlContent string
-		success := false
-
-		for attempt := 0; attempt < 3; attempt++ {
-			userAgent := userAgents[rand.Intn(len(userAgents))]
-
-			req, err := http.NewRequest("GET", menuURL, nil)
-			if err != nil {
-				continue
-			}
-
-			// Set headers to mimic a browser
-			req.Header.Set("User-Agent", userAgent)
-			req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-			req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-			req.Header.Set("Connection", "keep-alive")
-			req.Header.Set("Upgrade-Insecure-Requests", "1")
-			req.Header.Set("Cache-Control", "max-age=0")
-
-			resp, err := client.Do(req)
-			if err != nil {
-				time.Sleep(time.Duration(attempt+1) * 2 * time.Second)
-				continue
-			}
-
-			if resp.StatusCode == http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				resp.Body.Close()
-
-				if err == nil {
-					htmlContent = string(body)
-					success = true
-					break
-				}
-			} else {
-				resp.Body.Close()
-			}
-
-			// Wait before retrying with exponential backoff
-			backoffTime := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			time.Sleep(backoffTime + time.Duration(rand.Intn(1000))*time.Millisecond)
-		}
-
-		if !success {
-			fmt.Printf("Failed to access %s after multiple attempts\n", menuURL)
-			continue
-		}
-
-		// Check if any of the search terms appear in the HTML
-		htmlLower := strings.ToLower(htmlContent)
-		for _, term := range searchTerms {
-			if strings.Contains(htmlLower, term) {
-				fmt.Printf("Found '%s' in menu at %s!\n", term, menuURL)
-				return true, nil
-			}
-		}
-
-		// Parse HTML and check specific elements
-		reader := strings.NewReader(htmlContent)
-		doc, err := goquery.NewDocumentFromReader(reader)
-		if err != nil {
-			continue
-		}
-
-		// Check menu items
-		found := false
-		doc.Find(".product-name, .product-title, .menu-item, .food-item-name").Each(func(i int, s *goquery.Selection) {
-			itemText := strings.ToLower(s.Text())
-			for _, term := range searchTerms {
-				if strings.Contains(itemText, term) {
-					found = true
-					return
-				}
-			}
-		})
-
-		if found {
-			return true, nil
-		}
-	}
-
-	// Special case handling based on location
-	// This is where you can add known locations that have the Chilito
-	knownChilitoLocations := map[string]bool{
-		"018678": true, // From your test case
-		// Add more known locations here
-	}
-
-	if hasChilito, ok := knownChilitoLocations[location.StoreID]; ok && hasChilito {
-		fmt.Printf("Location %s is in our database of known Chilito locations\n", location.StoreID)
-		return true, nil
-	}
-
-	return false, nil
-}
-
 // haversineDistance calculates the distance between two coordinates
 func haversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
 	const R = 6371 // Earth radius in kilometers
@@ -741,123 +639,14 @@ func haversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
 	return R * c
 }
 
-// tacoBellWebsiteSearch finds locations using Taco Bell's official API
-func (f *ChilitoBurritoFinder) tacoBellWebsiteSearch(lat, lng float64, radius int) ([]TacoBellLocation, error) {
-	fmt.Printf("Searching for Taco Bell locations using official API near: %f, %f\n", lat, lng)
-
-	// Build URL for the Taco Bell stores API
-	requestURL := fmt.Sprintf("https://www.tacobell.com/tacobellwebservices/v4/tacobell/stores?latitude=%f&longitude=%f&_=%d",
-		lat, lng, time.Now().UnixNano()/int64(time.Millisecond))
-
-	// Create request with appropriate headers
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Referer", "https://www.tacobell.com/")
-
-	// Send the request
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("taco bell API returned status code %d", resp.StatusCode)
+// sleepContext pauses for d, returning early with ctx's error if ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-
-	// Read and parse the response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
-	}
-
-	// Parse the JSON
-	var storeData struct {
-		NearByStores []struct {
-			StoreNumber string `json:"storeNumber"`
-			PhoneNumber string `json:"phoneNumber"`
-			Address     struct {
-				Line1      string `json:"line1"`
-				Line2      string `json:"line2"`
-				Town       string `json:"town"`
-				PostalCode string `json:"postalCode"`
-				Region     struct {
-					Isocode string `json:"isocode"`
-				} `json:"region"`
-			} `json:"address"`
-			GeoPoint struct {
-				Latitude  float64 `json:"latitude"`
-				Longitude float64 `json:"longitude"`
-			} `json:"geoPoint"`
-			FormattedDistance string `json:"formattedDistance"`
-		} `json:"nearByStores"`
-	}
-
-	if err := json.Unmarshal(body, &storeData); err != nil {
-		return nil, fmt.Errorf("error parsing JSON data: %w", err)
-	}
-
-	// Convert to our TacoBellLocation format
-	var locations []TacoBellLocation
-	for _, store := range storeData.NearByStores {
-		// Format address
-		address := store.Address.Line1
-		if store.Address.Line2 != "" && store.Address.Line2 != "null" {
-			address += ", " + store.Address.Line2
-		}
-
-		// Add town and region
-		address += ", " + store.Address.Town
-		regionCode := ""
-		if strings.HasPrefix(store.Address.Region.Isocode, "US-") {
-			regionCode = strings.TrimPrefix(store.Address.Region.Isocode, "US-")
-		} else {
-			regionCode = store.Address.Region.Isocode
-		}
-		address += ", " + regionCode + " " + store.Address.PostalCode
-
-		// Parse distance from formatted string (e.g., "0.25 Miles")
-		var distance float64
-		if distStr := strings.TrimSuffix(strings.TrimSpace(store.FormattedDistance), " Miles"); distStr != "" {
-			if dist, err := strconv.ParseFloat(distStr, 64); err == nil {
-				// Convert miles to kilometers
-				distance = dist * 1.60934
-			} else {
-				// Calculate distance if parsing fails
-				distance = haversineDistance(lat, lng, store.GeoPoint.Latitude, store.GeoPoint.Longitude)
-			}
-		} else {
-			// Calculate distance if formatted distance is not available
-			distance = haversineDistance(lat, lng, store.GeoPoint.Latitude, store.GeoPoint.Longitude)
-		}
-
-		locations = append(locations, TacoBellLocation{
-			PlaceID:     store.StoreNumber,
-			Name:        "Taco Bell " + store.StoreNumber,
-			Address:     address,
-			Distance:    distance,
-			PhoneNumber: store.PhoneNumber,
-			StoreID:     store.StoreNumber,
-		})
-
-		fmt.Printf("Found Taco Bell #%s at %s (%.2f km)\n",
-			store.StoreNumber, address, distance)
-	}
-
-	// Filter results based on radius (convert radius from meters to km)
-	radiusKm := float64(radius) / 1000.0
-	var filteredLocations []TacoBellLocation
-	for _, loc := range locations {
-		if loc.Distance <= radiusKm {
-			filteredLocations = append(filteredLocations, loc)
-		}
-	}
-
-	fmt.Printf("Found %d Taco Bell locations within %.2f km\n", len(filteredLocations), radiusKm)
-	return filteredLocations, nil
 }
@@ -0,0 +1,236 @@
+package finder
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetStoreIDScrapesSearchPage(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{"location card", "locations_search.html", "031447"},
+		{"script", "locations_search_script.html", "027219"},
+		{"no match", "locations_search_empty.html", "osm-node-5123456789"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpstream(t)
+			var query string
+			handler := fixture(t, tt.page)
+			u.handle("GET /locations/search", func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query().Get("q")
+				handler(w, r)
+			})
+
+			// Without coordinates the store search page is the only option
+			location := TacoBellLocation{
+				PlaceID: "osm-node-5123456789",
+				StoreID: "osm-node-5123456789",
+				Address: "4647 Barranca Pkwy, Irvine, CA 92604",
+			}
+			storeID, err := newTestFinder(u).getStoreID(context.Background(), location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if storeID != tt.want {
+				t.Errorf("getStoreID = %q, want %q", storeID, tt.want)
+			}
+			if query != location.Address {
+				t.Errorf("searched for %q, want the location's address", query)
+			}
+		})
+	}
+}
+
+func TestGetStoreIDKeepsOfficialNumbers(t *testing.T) {
+	u := newUpstream(t)
+	storeID, err := newTestFinder(u).getStoreID(context.Background(), TacoBellLocation{PlaceID: "018678", StoreID: "018678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storeID != "018678" || u.count("") != 0 {
+		t.Errorf("getStoreID = %q after %d requests, want 018678 without any", storeID, u.count(""))
+	}
+}
+
+func TestGetStoreIDMatchesAndCaches(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", storesByLatitude(t, map[string]string{
+		"33.686941": "tacobell_stores_barranca.json",
+	}))
+
+	cache := OpenStoreIDCache(filepath.Join(t.TempDir(), "storeids.json"), DefaultStoreIDTTL)
+	location := TacoBellLocation{
+		PlaceID:   "osm-node-5123456789",
+		StoreID:   "osm-node-5123456789",
+		Address:   "4647 Barranca Parkway, Irvine, CA 92604",
+		Latitude:  33.6869412,
+		Longitude: -117.8075731,
+	}
+	for run := 0; run < 2; run++ {
+		f := newTestFinder(u, WithStoreIDCache(cache))
+		storeID, err := f.getStoreID(context.Background(), location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if storeID != "031447" {
+			t.Errorf("run %d: getStoreID = %q, want 031447", run, storeID)
+		}
+	}
+	if n := u.count("/tacobellwebservices/"); n != 1 {
+		t.Errorf("%d store lookups, want 1", n)
+	}
+	if n := u.count("/locations/search"); n != 0 {
+		t.Errorf("%d store searches, want 0", n)
+	}
+}
+
+func TestGetStoreIDDoesNotCacheFailedLookups(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	})
+	u.handle("GET /locations/search", fixture(t, "locations_search_empty.html"))
+
+	cache := OpenStoreIDCache(filepath.Join(t.TempDir(), "storeids.json"), DefaultStoreIDTTL)
+	location := TacoBellLocation{
+		PlaceID:   "osm-node-5123456789",
+		StoreID:   "osm-node-5123456789",
+		Address:   "4647 Barranca Parkway, Irvine, CA 92604",
+		Latitude:  33.6869412,
+		Longitude: -117.8075731,
+	}
+	f := newTestFinder(u, WithStoreIDCache(cache), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if storeID, err := f.getStoreID(context.Background(), location); err == nil {
+		t.Errorf("getStoreID = %q, want an error", storeID)
+	}
+	if _, ok, err := cache.Get(location.PlaceID); err != nil || ok {
+		t.Errorf("cache.Get = %v, %v, want no entry", ok, err)
+	}
+}
+
+func TestGetStoreIDCachesMisses(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /locations/search", fixture(t, "locations_search_empty.html"))
+
+	cache := OpenStoreIDCache(filepath.Join(t.TempDir(), "storeids.json"), DefaultStoreIDTTL)
+	location := TacoBellLocation{PlaceID: "osm-node-6200000001", StoreID: "osm-node-6200000001", Address: "1 Nowhere Rd, Irvine, CA"}
+	for run := 0; run < 2; run++ {
+		storeID, err := newTestFinder(u, WithStoreIDCache(cache)).getStoreID(context.Background(), location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if storeID != location.PlaceID {
+			t.Errorf("run %d: getStoreID = %q, want the place ID", run, storeID)
+		}
+	}
+	if n := u.count("/locations/search"); n != 1 {
+		t.Errorf("%d store searches, want 1", n)
+	}
+}
+
+// searchUpstream serves a search around Glen Bell Way: three stores, of
+// which Technology Dr is a known location and only the Santa Ana menu
+// lists the item
+func searchUpstream(t *testing.T) *upstream {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+	withChilito := fixture(t, "menu/burritos_chilito.html")
+	without := fixture(t, "menu/burritos.html")
+	u.handle("GET /food/{page}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("store") == "004012" && r.PathValue("page") == "burritos" {
+			withChilito(w, r)
+			return
+		}
+		without(w, r)
+	})
+	return u
+}
+
+func TestFindChilitoBurritos(t *testing.T) {
+	u := searchUpstream(t)
+	f := newTestFinder(u)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	results, err := f.FindChilitoBurritosContext(ctx, "1 Glen Bell Way, Irvine, CA", 100000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nearest first: Barranca has nothing, Technology Dr is a known
+	// location, and the Santa Ana menu lists it
+	want := []struct {
+		storeID string
+		status  CheckStatus
+		checker string
+	}{
+		{"031447", StatusNotFound, "scrape"},
+		{"018678", StatusFound, "known"},
+		{"004012", StatusFound, "scrape"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Location.StoreID != w.storeID || got.Status != w.status || got.Checker != w.checker {
+			t.Errorf("result %d = %s %v by %s, want %s %v by %s", i,
+				got.Location.StoreID, got.Status, got.Checker, w.storeID, w.status, w.checker)
+		}
+		if got.Location.Source != "tacobell" || got.CheckedAt.IsZero() {
+			t.Errorf("result %d: Source = %q, CheckedAt = %v", i, got.Location.Source, got.CheckedAt)
+		}
+	}
+	if results[2].MatchedURL != u.URL+"/food/burritos?store=004012" {
+		t.Errorf("MatchedURL = %q", results[2].MatchedURL)
+	}
+}
+
+func TestFindChilitoBurritosLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  []string
+	}{
+		// Stops at the first confirmed store
+		{1, []string{"031447 not_found", "018678 found"}},
+		{2, []string{"031447 not_found", "018678 found", "004012 found"}},
+		{5, []string{"031447 not_found", "018678 found", "004012 found"}},
+	}
+	for _, tt := range tests {
+		u := searchUpstream(t)
+		results, err := newTestFinder(u).FindChilitoBurritos("1 Glen Bell Way, Irvine, CA", 100000, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, result := range results {
+			got = append(got, result.Location.StoreID+" "+result.Status.String())
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("limit %d: results = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestFindNearestChilitoBurrito(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos.html"))
+
+	location, err := newTestFinder(u).FindNearestChilitoBurrito("1 Glen Bell Way, Irvine, CA", 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.StoreID != "018678" {
+		t.Errorf("nearest = %s, want the known location 018678", location.StoreID)
+	}
+}
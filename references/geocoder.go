@@ -0,0 +1,256 @@
+package finder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Geocoder converts a free-form address into coordinates
+type Geocoder interface {
+	// Name identifies the provider (e.g. "nominatim") for ordering and logging
+	Name() string
+	// Geocode returns the latitude and longitude of address
+	Geocode(ctx context.Context, address string) (float64, float64, error)
+}
+
+// Default endpoints and identifiers for the built-in geocoders
+const (
+	DefaultNominatimEndpoint  = "https://nominatim.openstreetmap.org/search"
+	DefaultNominatimUserAgent = "ChilitoBurritoFinder/1.0 (github.com/yourusername/chilito)"
+	DefaultMapboxToken        = "MAPBOX_TOKEN_PLACEHOLDER"
+)
+
+// DefaultGeocoders returns the built-in geocoder chain in its default order:
+// Taco Bell's API first, then Mapbox, then OpenStreetMap's Nominatim
+func DefaultGeocoders(client *http.Client) []Geocoder {
+	return DefaultConfig().geocoders(client)
+}
+
+// defaultClient is used by providers without a Client of their own
+var defaultClient = &http.Client{
+	Timeout:   DefaultTimeouts.Request,
+	Transport: DefaultRetryPolicy.Transport(sharedTransport()),
+}
+
+// httpClient returns client, or a client that retries with
+// DefaultRetryPolicy if it is nil
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return defaultClient
+	}
+	return client
+}
+
+// TacoBellGeocoder geocodes using Taco Bell's official location API
+type TacoBellGeocoder struct {
+	Client *http.Client
+	// BaseURL is the API's base URL (defaults to DefaultTacoBellAPIURL)
+	BaseURL string
+}
+
+// Name implements Geocoder
+func (g *TacoBellGeocoder) Name() string { return "tacobell" }
+
+// Geocode implements Geocoder
+func (g *TacoBellGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	// Use Taco Bell's official geocoding API
+	encodedAddress := url.QueryEscape(address)
+	requestURL := fmt.Sprintf("%s/location/v1/%s", stringOr(g.BaseURL, DefaultTacoBellAPIURL), encodedAddress)
+
+	// Create request with headers
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers to mimic browser behavior
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Referer", "https://www.tacobell.com/")
+
+	// Send the request
+	loggerFrom(ctx).Debug("geocoding", "provider", g.Name(), "url", requestURL)
+	resp, err := httpClient(g.Client).Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("taco bell API returned status code %d", resp.StatusCode)
+	}
+
+	// Parse the JSON response
+	var result struct {
+		Geometry struct {
+			Lat float64 `json:"lat"`
+			Lng float64 `json:"lng"`
+		} `json:"geometry"`
+		Success bool `json:"success"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, 0, fmt.Errorf("error parsing JSON response: %w", err)
+	}
+
+	if !result.Success {
+		return 0, 0, fmt.Errorf("taco Bell API geocoding was not successful")
+	}
+
+	return result.Geometry.Lat, result.Geometry.Lng, nil
+}
+
+// NominatimGeocoder geocodes using an OpenStreetMap Nominatim instance.
+// Point Endpoint at a self-hosted instance to avoid the public usage limits.
+type NominatimGeocoder struct {
+	Client *http.Client
+	// Endpoint is the search URL (defaults to DefaultNominatimEndpoint)
+	Endpoint string
+	// UserAgent identifies the application, as Nominatim's policy requires
+	// (defaults to DefaultNominatimUserAgent)
+	UserAgent string
+}
+
+// Name implements Geocoder
+func (g *NominatimGeocoder) Name() string { return "nominatim" }
+
+// Geocode implements Geocoder
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultNominatimEndpoint
+	}
+	userAgent := g.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultNominatimUserAgent
+	}
+
+	params := url.Values{}
+	params.Add("q", address)
+	params.Add("format", "json")
+	params.Add("limit", "1")
+	params.Add("addressdetails", "1")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set required User-Agent for Nominatim
+	req.Header.Set("User-Agent", userAgent)
+
+	loggerFrom(ctx).Debug("geocoding", "provider", g.Name(), "url", req.URL.String())
+	resp, err := httpClient(g.Client).Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+
+	if err := json.Unmarshal(body, &results); err != nil {
+		return 0, 0, fmt.Errorf("error parsing JSON response: %w", err)
+	}
+	loggerFrom(ctx).Debug("geocoding response", "provider", g.Name(), "status", resp.StatusCode, "results", len(results))
+
+	if len(results) == 0 {
+		return 0, 0, errors.New("no geocoding results returned")
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude: %w", err)
+	}
+
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude: %w", err)
+	}
+
+	return lat, lng, nil
+}
+
+// MapboxGeocoder geocodes using the Mapbox geocoding API
+type MapboxGeocoder struct {
+	Client *http.Client
+	// Token is the Mapbox access token (defaults to DefaultMapboxToken)
+	Token string
+	// BaseURL is the API's base URL (defaults to DefaultMapboxURL)
+	BaseURL string
+}
+
+// Name implements Geocoder
+func (g *MapboxGeocoder) Name() string { return "mapbox" }
+
+// Geocode implements Geocoder
+func (g *MapboxGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	// Using a placeholder token - in production you'd use your own token
+	token := g.Token
+	if token == "" {
+		token = DefaultMapboxToken
+	}
+	encodedAddress := url.QueryEscape(address)
+
+	endpoint := fmt.Sprintf("%s/geocoding/v5/mapbox.places/%s.json?access_token=%s",
+		stringOr(g.BaseURL, DefaultMapboxURL), encodedAddress, token)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// The URL carries the access token, so only the provider is logged and
+	// the URL in request errors is redacted
+	loggerFrom(ctx).Debug("geocoding", "provider", g.Name())
+	resp, err := httpClient(g.Client).Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(req.URL)
+		}
+		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Features []struct {
+			Center []float64 `json:"center"` // [longitude, latitude]
+		} `json:"features"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, 0, fmt.Errorf("error parsing JSON response: %w", err)
+	}
+
+	if len(result.Features) == 0 || len(result.Features[0].Center) < 2 {
+		return 0, 0, errors.New("no geocoding results returned")
+	}
+
+	// Mapbox returns [lng, lat] whereas most APIs use [lat, lng]
+	lng := result.Features[0].Center[0]
+	lat := result.Features[0].Center[1]
+
+	return lat, lng, nil
+}
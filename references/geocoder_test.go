@@ -0,0 +1,167 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGeocoders(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /geocoding/v5/mapbox.places/{query}", fixture(t, "mapbox_geocode.json"))
+	u.handle("GET /search", fixture(t, "nominatim_search.json"))
+
+	tests := []struct {
+		geocoder Geocoder
+		lat, lng float64
+	}{
+		{&TacoBellGeocoder{BaseURL: u.URL}, 33.684567, -117.826505},
+		{&MapboxGeocoder{BaseURL: u.URL}, 33.684611, -117.826418},
+		{&NominatimGeocoder{Endpoint: u.URL + "/search"}, 33.6846102, -117.8264913},
+	}
+	for _, tt := range tests {
+		t.Run(tt.geocoder.Name(), func(t *testing.T) {
+			lat, lng, err := tt.geocoder.Geocode(context.Background(), "1 Glen Bell Way, Irvine, CA")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !closeTo(lat, tt.lat) || !closeTo(lng, tt.lng) {
+				t.Errorf("Geocode = %v, %v; want %v, %v", lat, lng, tt.lat, tt.lng)
+			}
+		})
+	}
+}
+
+func TestGeocodeFallback(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode_failed.json"))
+	u.handle("GET /geocoding/v5/mapbox.places/{query}", fixtureStatus(t, http.StatusUnauthorized, "mapbox_unauthorized.json"))
+	u.handle("GET /search", fixture(t, "nominatim_search.json"))
+
+	f := newTestFinder(u)
+	lat, lng, err := f.geocodeAddress(context.Background(), "1 Glen Bell Way, Irvine, CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(lat, 33.6846102) || !closeTo(lng, -117.8264913) {
+		t.Errorf("geocodeAddress = %v, %v; want the Nominatim result", lat, lng)
+	}
+	for _, prefix := range []string{"/location/v1/", "/geocoding/v5/", "/search"} {
+		if n := u.count(prefix); n != 1 {
+			t.Errorf("%d requests to %s, want 1", n, prefix)
+		}
+	}
+}
+
+func TestGeocodeAllProvidersFail(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode_failed.json"))
+	u.handle("GET /geocoding/v5/mapbox.places/{query}", fixtureStatus(t, http.StatusUnauthorized, "mapbox_unauthorized.json"))
+	u.handle("GET /search", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("[]")) })
+
+	f := newTestFinder(u)
+	if _, _, err := f.geocodeAddress(context.Background(), "nowhere"); err == nil {
+		t.Fatal("geocodeAddress succeeded, want an error")
+	}
+}
+
+func TestGeocodeOrder(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /search", fixture(t, "nominatim_search.json"))
+
+	f := newTestFinder(u, WithGeocoderOrder("nominatim", "tacobell"))
+	lat, _, err := f.geocodeAddress(context.Background(), "1 Glen Bell Way, Irvine, CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(lat, 33.6846102) {
+		t.Errorf("lat = %v, want the Nominatim result", lat)
+	}
+	if n := u.count("/location/v1/"); n != 0 {
+		t.Errorf("%d requests to the Taco Bell geocoder, want 0", n)
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// failingTransport fails every request
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMapboxTokenNotLogged(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MapboxToken = "pk.secret-token"
+	var logs bytes.Buffer
+	f := NewChilitoBurritoFinder(WithConfig(cfg), WithGeocoderOrder("mapbox"), WithTransport(failingTransport{}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	_, _, err := f.geocodeAddress(context.Background(), "1 Glen Bell Way, Irvine, CA")
+	if err == nil {
+		t.Fatal("geocoding succeeded without a working transport")
+	}
+	if strings.Contains(err.Error(), "secret-token") || strings.Contains(logs.String(), "secret-token") {
+		t.Errorf("the token leaked:\nerror: %v\nlogs: %s", err, logs.String())
+	}
+	if !strings.Contains(logs.String(), "access_token=REDACTED") {
+		t.Errorf("logs = %s, want the redacted request URL", logs.String())
+	}
+}
+
+// fixedGeocoder geocodes every address to the same point
+type fixedGeocoder struct {
+	name     string
+	lat, lng float64
+}
+
+func (g fixedGeocoder) Name() string { return g.name }
+
+func (g fixedGeocoder) Geocode(ctx context.Context, address string) (float64, float64, error) {
+	return g.lat, g.lng, nil
+}
+
+func TestGeocoderChainOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"defaults", nil, []string{"tacobell", "mapbox", "nominatim"}},
+		{"replaced", []Option{WithGeocoders(fixedGeocoder{name: "a"}, fixedGeocoder{name: "b"})}, []string{"a", "b"}},
+		{"registered", []Option{WithGeocoder(fixedGeocoder{name: "custom"})}, []string{"tacobell", "mapbox", "nominatim", "custom"}},
+		{"replaced by name", []Option{WithGeocoder(fixedGeocoder{name: "mapbox"})}, []string{"tacobell", "mapbox", "nominatim"}},
+		{"disabled", []Option{WithoutGeocoders("mapbox")}, []string{"tacobell", "nominatim"}},
+		{"reordered", []Option{WithGeocoder(fixedGeocoder{name: "custom"}), WithGeocoderOrder("custom", "tacobell", "missing")}, []string{"custom", "tacobell"}},
+	}
+	for _, tt := range tests {
+		got := NewChilitoBurritoFinder(tt.opts...).Geocoders()
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: Geocoders() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegisteredGeocoderReplacesBuiltIn(t *testing.T) {
+	// A self-hosted "nominatim" takes the built-in one's place in the chain
+	u := newUpstream(t)
+	f := newTestFinder(u, WithGeocoder(fixedGeocoder{name: "nominatim", lat: 10, lng: 20}), WithGeocoderOrder("nominatim"))
+	lat, lng, err := f.geocodeAddress(context.Background(), "1 Glen Bell Way, Irvine, CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lat != 10 || lng != 20 || u.count("") != 0 {
+		t.Errorf("geocoded to %v, %v after %d requests, want the registered geocoder's answer", lat, lng, u.count(""))
+	}
+}
@@ -0,0 +1,74 @@
+package finder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Item describes a menu item to look for
+type Item struct {
+	// Name is the item's menu name; it is always searched for
+	Name string `yaml:"name"`
+	// Aliases are other names the item is listed under
+	Aliases []string `yaml:"aliases,omitempty"`
+	// Exclude lists names that contain a search term but are a different
+	// item, e.g. "mexican pizza sauce" when looking for the Mexican Pizza
+	Exclude []string `yaml:"exclude,omitempty"`
+	// Selectors replace the configured menu selectors for this item
+	Selectors []string `yaml:"selectors,omitempty"`
+	// Paths replace the configured menu pages for this item
+	Paths []string `yaml:"paths,omitempty"`
+	// KnownStores are store numbers known to sell the item
+	KnownStores []string `yaml:"known_stores,omitempty"`
+}
+
+// DefaultItemKey names ChiliCheeseBurrito in Config.Items
+const DefaultItemKey = "chilito"
+
+// ChiliCheeseBurrito is the item searched for by default
+var ChiliCheeseBurrito = Item{
+	Name:        "Chili Cheese Burrito",
+	Aliases:     DefaultSearchTerms,
+	KnownStores: DefaultKnownChilitoStores,
+}
+
+// SearchTerms returns the item's name and aliases, lowercased and without
+// duplicates
+func (i Item) SearchTerms() []string {
+	return lowerUnique(append([]string{i.Name}, i.Aliases...))
+}
+
+// ExcludeTerms returns the lowercased exclusion terms
+func (i Item) ExcludeTerms() []string {
+	return lowerUnique(i.Exclude)
+}
+
+// key identifies the item in the menu cache. Besides the name it covers
+// everything that decides whether a menu lists the item, the search terms,
+// exclusions and match threshold, so changing any of them does not reuse
+// answers found with the old ones.
+func (i Item) key(threshold float64) string {
+	terms, exclude := i.SearchTerms(), i.ExcludeTerms()
+	sort.Strings(terms)
+	sort.Strings(exclude)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%q %q %g", terms, exclude, threshold)))
+	return strings.ToLower(strings.TrimSpace(i.Name)) + "#" + hex.EncodeToString(sum[:4])
+}
+
+// lowerUnique lowercases terms and drops empty and repeated ones
+func lowerUnique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	var unique []string
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		unique = append(unique, term)
+	}
+	return unique
+}
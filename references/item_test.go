@@ -0,0 +1,65 @@
+package finder
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLookupItem(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, name := range []string{"chilito", "CHILITO", "Chili Cheese Burrito", "ccb"} {
+		if got := cfg.LookupItem(name); got.Name != ChiliCheeseBurrito.Name {
+			t.Errorf("LookupItem(%q) = %q, want the built-in definition", name, got.Name)
+		}
+	}
+	if got := cfg.LookupItem(" Mexican Pizza "); got.Name != "Mexican Pizza" || len(got.SearchTerms()) != 1 {
+		t.Errorf("LookupItem(Mexican Pizza) = %+v, want an ad-hoc item", got)
+	}
+}
+
+func TestScrapingMenuCheckerExclusions(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos.html"))
+
+	// "Beefy" only appears as part of the Beefy 5-Layer Burrito
+	tests := []struct {
+		exclude []string
+		want    CheckStatus
+	}{
+		{nil, StatusFound},
+		{[]string{"beefy 5-layer burrito"}, StatusNotFound},
+	}
+	for _, tt := range tests {
+		checker := &ScrapingMenuChecker{
+			BaseURL:     u.URL,
+			SearchTerms: []string{"Beefy"},
+			Exclude:     tt.exclude,
+			Paths:       []string{"/food/burritos"},
+		}
+		result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+		if result.Status != tt.want {
+			t.Errorf("exclude %v: status = %v (%q), want %v", tt.exclude, result.Status, result.Evidence, tt.want)
+		}
+	}
+}
+
+func TestItemKey(t *testing.T) {
+	item := Item{Name: "Mexican Pizza", Aliases: []string{"mex pizza", "pizza"}, Exclude: []string{"mexican pizza sauce"}}
+	key := item.key(DefaultMatchThreshold)
+
+	same := Item{Name: "mexican pizza", Aliases: []string{"Pizza", "Mex Pizza"}, Exclude: []string{"Mexican Pizza Sauce"}}
+	if got := same.key(DefaultMatchThreshold); got != key {
+		t.Errorf("key = %q, want %q for the same terms in another order and case", got, key)
+	}
+
+	changed := map[string]string{
+		"threshold": item.key(0.9),
+		"aliases":   Item{Name: item.Name, Aliases: []string{"pizza"}, Exclude: item.Exclude}.key(DefaultMatchThreshold),
+		"exclude":   Item{Name: item.Name, Aliases: item.Aliases}.key(DefaultMatchThreshold),
+	}
+	for what, got := range changed {
+		if got == key {
+			t.Errorf("key unchanged after changing the %s", what)
+		}
+	}
+}
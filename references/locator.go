@@ -0,0 +1,444 @@
+package finder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StoreLocator discovers Taco Bell restaurants around a point
+type StoreLocator interface {
+	// Name identifies the source (e.g. "overpass"); it is recorded in
+	// TacoBellLocation.Source for every location the locator returns
+	Name() string
+	// Locate returns the restaurants within radius meters of lat, lng
+	Locate(ctx context.Context, lat, lng float64, radius int) ([]TacoBellLocation, error)
+}
+
+// DefaultOverpassEndpoint is the public Overpass API interpreter
+const DefaultOverpassEndpoint = "https://overpass-api.de/api/interpreter"
+
+// duplicateDistanceKm is how close two locations from different locators
+// must be to be treated as the same restaurant when merging
+const duplicateDistanceKm = 0.15
+
+// Official stores are paired with an OSM location when they are within
+// duplicateDistanceKm of it, or within storeMatchRadius meters and have a
+// similar address
+const storeMatchRadius = 1000
+
+// DefaultStoreLocators returns the built-in locators in their default order:
+// Taco Bell's official store API first, then OpenStreetMap's Overpass API
+func DefaultStoreLocators(client *http.Client) []StoreLocator {
+	return DefaultConfig().locators(client)
+}
+
+// findTacoBellLocations finds Taco Bell restaurants near coordinates. By
+// default the first locator that succeeds wins; in merge mode every locator
+// runs and their results are combined.
+func (f *ChilitoBurritoFinder) findTacoBellLocations(ctx context.Context, lat, lng float64, radius int) ([]TacoBellLocation, error) {
+	f.logger.Info("searching for Taco Bell locations", "lat", lat, "lng", lng, "radius_m", radius)
+
+	if len(f.locators) == 0 {
+		return nil, fmt.Errorf("no store locators configured")
+	}
+
+	var locations []TacoBellLocation
+	var lastErr error
+	succeeded := false
+	for _, locator := range f.locators {
+		found, err := locator.Locate(ctx, lat, lng, radius)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = fmt.Errorf("%s: %w", locator.Name(), err)
+			f.logger.Warn("store locator failed", "provider", locator.Name(), "error", err)
+			continue
+		}
+		for i := range found {
+			found[i].Source = locator.Name()
+			f.logger.Debug("found Taco Bell", "provider", locator.Name(), "store_id", found[i].StoreID,
+				"address", found[i].Address, "distance_km", found[i].Distance)
+		}
+		f.logger.Info("store locator succeeded", "provider", locator.Name(), "count", len(found))
+		succeeded = true
+
+		if !f.mergeLocators {
+			locations = found
+			break
+		}
+		locations = mergeLocations(locations, found)
+	}
+
+	if !succeeded {
+		return nil, fmt.Errorf("all search methods failed: %w", lastErr)
+	}
+
+	f.logger.Info("Taco Bell locations found", "count", len(locations))
+	return locations, nil
+}
+
+// mergeLocations appends the locations in extra that are not already present
+// in base. Earlier locators take precedence, so an official store record is
+// kept over an OpenStreetMap node for the same restaurant.
+func mergeLocations(base, extra []TacoBellLocation) []TacoBellLocation {
+	for _, candidate := range extra {
+		duplicate := false
+		for i, existing := range base {
+			if sameRestaurant(existing, candidate) {
+				if base[i].PhoneNumber == "" {
+					base[i].PhoneNumber = candidate.PhoneNumber
+				}
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			base = append(base, candidate)
+		}
+	}
+	return base
+}
+
+// sameRestaurant reports whether two locations describe the same restaurant
+func sameRestaurant(a, b TacoBellLocation) bool {
+	if a.StoreID != "" && a.StoreID == b.StoreID {
+		return true
+	}
+	if a.hasCoordinates() && b.hasCoordinates() {
+		// Similar addresses only count for nearby locations; a long street
+		// has many restaurants on it
+		distance := haversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		return distance <= duplicateDistanceKm ||
+			(distance*1000 <= storeMatchRadius && sameAddress(a.Address, b.Address))
+	}
+	return sameAddress(a.Address, b.Address)
+}
+
+// sameAddress reports whether two addresses are similar and don't have
+// different house numbers
+func sameAddress(a, b string) bool {
+	if a == "Address unknown" || b == "Address unknown" {
+		return false
+	}
+	numberA, numberB := houseNumber(a), houseNumber(b)
+	if numberA != "" && numberB != "" && numberA != numberB {
+		return false
+	}
+	return similarAddresses(a, b)
+}
+
+// houseNumber returns the leading house number of an address, or "" if it
+// doesn't start with one
+func houseNumber(address string) string {
+	fields := strings.Fields(normalizeAddress(address))
+	if len(fields) == 0 || strings.Trim(fields[0], "0123456789") != "" {
+		return ""
+	}
+	return fields[0]
+}
+
+// matchOfficialStore asks locator for the official stores around an OSM
+// location and returns the store number of the one it describes, or "" if
+// none does
+func matchOfficialStore(ctx context.Context, locator StoreLocator, location TacoBellLocation) (string, error) {
+	candidates, err := locator.Locate(ctx, location.Latitude, location.Longitude, storeMatchRadius)
+	if err != nil {
+		return "", err
+	}
+
+	best := ""
+	bestDistance := math.MaxFloat64
+	for _, candidate := range candidates {
+		if !isStoreNumber(candidate.StoreID) || !candidate.hasCoordinates() {
+			continue
+		}
+		distance := haversineDistance(location.Latitude, location.Longitude, candidate.Latitude, candidate.Longitude)
+		if distance > duplicateDistanceKm && !sameAddress(location.Address, candidate.Address) {
+			continue
+		}
+		if distance < bestDistance {
+			best, bestDistance = candidate.StoreID, distance
+		}
+	}
+	return best, nil
+}
+
+// OverpassLocator searches OpenStreetMap for Taco Bell restaurants using the
+// Overpass API. Locations it returns carry "osm-<type>-<id>" placeholder IDs
+// rather than official store numbers.
+type OverpassLocator struct {
+	Client *http.Client
+	// Endpoint is the interpreter URL (defaults to DefaultOverpassEndpoint)
+	Endpoint string
+}
+
+// Name implements StoreLocator
+func (l *OverpassLocator) Name() string { return "overpass" }
+
+// Locate implements StoreLocator
+func (l *OverpassLocator) Locate(ctx context.Context, lat, lng float64, radius int) ([]TacoBellLocation, error) {
+	endpoint := l.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOverpassEndpoint
+	}
+
+	// Convert radius from meters to degrees (approximate)
+	radiusDegrees := float64(radius) / 111000.0 // 1 degree is roughly 111 km
+
+	// Build Overpass query to find Taco Bell locations. Overpass bounding
+	// boxes are south, west, north, east.
+	bbox := fmt.Sprintf("%.6f,%.6f,%.6f,%.6f",
+		lat-radiusDegrees, lng-radiusDegrees,
+		lat+radiusDegrees, lng+radiusDegrees)
+
+	query := fmt.Sprintf(`[out:json];
+		(
+		  node["amenity"="fast_food"]["name"~"Taco Bell",i](%s);
+		  way["amenity"="fast_food"]["name"~"Taco Bell",i](%s);
+		  relation["amenity"="fast_food"]["name"~"Taco Bell",i](%s);
+		);
+		out center;`, bbox, bbox, bbox)
+
+	// URL encode the query
+	encoded := url.QueryEscape(query)
+	requestURL := endpoint + "?data=" + encoded
+
+	loggerFrom(ctx).Debug("searching stores", "provider", l.Name(), "url", endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient(l.Client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("overpass API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Elements []struct {
+			Type string `json:"type"`
+			ID   int64  `json:"id"`
+			Tags struct {
+				Name        string `json:"name"`
+				Housenumber string `json:"addr:housenumber"`
+				Street      string `json:"addr:street"`
+				City        string `json:"addr:city"`
+				State       string `json:"addr:state"`
+				Postcode    string `json:"addr:postcode"`
+				Phone       string `json:"phone"`
+			} `json:"tags"`
+			Lat    float64 `json:"lat"`
+			Lon    float64 `json:"lon"`
+			Center struct {
+				Lat float64 `json:"lat"`
+				Lon float64 `json:"lon"`
+			} `json:"center"`
+		} `json:"elements"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var locations []TacoBellLocation
+	for _, element := range result.Elements {
+		// Get coordinates based on element type
+		nodeLat, nodeLng := element.Lat, element.Lon
+		if element.Type != "node" {
+			// For ways and relations, use center
+			nodeLat, nodeLng = element.Center.Lat, element.Center.Lon
+		}
+
+		// Build address from components
+		address := ""
+		if element.Tags.Housenumber != "" && element.Tags.Street != "" {
+			address = element.Tags.Housenumber + " " + element.Tags.Street
+		}
+		if element.Tags.City != "" {
+			if address != "" {
+				address += ", "
+			}
+			address += element.Tags.City
+		}
+		if element.Tags.State != "" {
+			if address != "" {
+				address += ", "
+			}
+			address += element.Tags.State
+		}
+		if element.Tags.Postcode != "" {
+			if address != "" {
+				address += " "
+			}
+			address += element.Tags.Postcode
+		}
+
+		if address == "" {
+			address = "Address unknown"
+		}
+
+		// Calculate distance
+		distance := haversineDistance(lat, lng, nodeLat, nodeLng)
+
+		// Build unique ID for OSM elements
+		placeID := fmt.Sprintf("osm-%s-%d", element.Type, element.ID)
+
+		locations = append(locations, TacoBellLocation{
+			PlaceID:     placeID,
+			Name:        element.Tags.Name,
+			Address:     address,
+			Distance:    distance,
+			PhoneNumber: element.Tags.Phone,
+			StoreID:     placeID, // Use the OSM ID as a fallback store ID
+			Latitude:    nodeLat,
+			Longitude:   nodeLng,
+		})
+	}
+
+	return locations, nil
+}
+
+// TacoBellLocator finds locations using Taco Bell's official store API
+type TacoBellLocator struct {
+	Client *http.Client
+	// BaseURL is the website's base URL (defaults to DefaultTacoBellURL)
+	BaseURL string
+}
+
+// Name implements StoreLocator
+func (l *TacoBellLocator) Name() string { return "tacobell" }
+
+// Locate implements StoreLocator
+func (l *TacoBellLocator) Locate(ctx context.Context, lat, lng float64, radius int) ([]TacoBellLocation, error) {
+	// Build URL for the Taco Bell stores API
+	requestURL := fmt.Sprintf("%s/tacobellwebservices/v4/tacobell/stores?latitude=%f&longitude=%f&_=%d",
+		stringOr(l.BaseURL, DefaultTacoBellURL), lat, lng, time.Now().UnixNano()/int64(time.Millisecond))
+
+	// Create request with appropriate headers
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Referer", "https://www.tacobell.com/")
+
+	// Send the request
+	loggerFrom(ctx).Debug("searching stores", "provider", l.Name(), "url", requestURL)
+	resp, err := httpClient(l.Client).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("taco bell API returned status code %d", resp.StatusCode)
+	}
+
+	// Read and parse the response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	// Parse the JSON
+	var storeData struct {
+		NearByStores []struct {
+			StoreNumber string `json:"storeNumber"`
+			PhoneNumber string `json:"phoneNumber"`
+			Address     struct {
+				Line1      string `json:"line1"`
+				Line2      string `json:"line2"`
+				Town       string `json:"town"`
+				PostalCode string `json:"postalCode"`
+				Region     struct {
+					Isocode string `json:"isocode"`
+				} `json:"region"`
+			} `json:"address"`
+			GeoPoint struct {
+				Latitude  float64 `json:"latitude"`
+				Longitude float64 `json:"longitude"`
+			} `json:"geoPoint"`
+			FormattedDistance string `json:"formattedDistance"`
+		} `json:"nearByStores"`
+	}
+
+	if err := json.Unmarshal(body, &storeData); err != nil {
+		return nil, fmt.Errorf("error parsing JSON data: %w", err)
+	}
+
+	// Convert to our TacoBellLocation format
+	var locations []TacoBellLocation
+	for _, store := range storeData.NearByStores {
+		// Format address
+		address := store.Address.Line1
+		if store.Address.Line2 != "" && store.Address.Line2 != "null" {
+			address += ", " + store.Address.Line2
+		}
+
+		// Add town and region
+		address += ", " + store.Address.Town
+		regionCode := ""
+		if strings.HasPrefix(store.Address.Region.Isocode, "US-") {
+			regionCode = strings.TrimPrefix(store.Address.Region.Isocode, "US-")
+		} else {
+			regionCode = store.Address.Region.Isocode
+		}
+		address += ", " + regionCode + " " + store.Address.PostalCode
+
+		// Parse distance from formatted string (e.g., "0.25 Miles")
+		var distance float64
+		if distStr := strings.TrimSuffix(strings.TrimSpace(store.FormattedDistance), " Miles"); distStr != "" {
+			if dist, err := strconv.ParseFloat(distStr, 64); err == nil {
+				// Convert miles to kilometers
+				distance = dist * 1.60934
+			} else {
+				// Calculate distance if parsing fails
+				distance = haversineDistance(lat, lng, store.GeoPoint.Latitude, store.GeoPoint.Longitude)
+			}
+		} else {
+			// Calculate distance if formatted distance is not available
+			distance = haversineDistance(lat, lng, store.GeoPoint.Latitude, store.GeoPoint.Longitude)
+		}
+
+		locations = append(locations, TacoBellLocation{
+			PlaceID:     store.StoreNumber,
+			Name:        "Taco Bell " + store.StoreNumber,
+			Address:     address,
+			Distance:    distance,
+			PhoneNumber: store.PhoneNumber,
+			StoreID:     store.StoreNumber,
+			Latitude:    store.GeoPoint.Latitude,
+			Longitude:   store.GeoPoint.Longitude,
+		})
+	}
+
+	// Filter results based on radius (convert radius from meters to km)
+	radiusKm := float64(radius) / 1000.0
+	var filteredLocations []TacoBellLocation
+	for _, loc := range locations {
+		if loc.Distance <= radiusKm {
+			filteredLocations = append(filteredLocations, loc)
+		}
+	}
+
+	loggerFrom(ctx).Debug("filtered stores by radius", "provider", l.Name(),
+		"total", len(locations), "within_radius", len(filteredLocations))
+	return filteredLocations, nil
+}
@@ -0,0 +1,228 @@
+package finder
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Coordinates of the address geocoded by the fixtures
+const testLat, testLng = 33.684567, -117.826505
+
+func TestTacoBellLocator(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+
+	locator := &TacoBellLocator{BaseURL: u.URL}
+	locations, err := locator.Locate(context.Background(), testLat, testLng, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The Santa Ana store is about 8.4 km away and outside the radius
+	want := []struct {
+		storeID, address, phone string
+		distance                float64
+	}{
+		{"018678", "15 Technology Dr, Irvine, CA 92618", "(949) 555-0142", 4.55 * 1.60934},
+		{"031447", "4647 Barranca Pkwy, Suite B, Irvine, CA 92604", "(949) 555-0199", 1.17 * 1.60934},
+	}
+	if len(locations) != len(want) {
+		t.Fatalf("got %d locations, want %d: %+v", len(locations), len(want), locations)
+	}
+	for i, w := range want {
+		got := locations[i]
+		if got.StoreID != w.storeID || got.PlaceID != w.storeID || got.Address != w.address ||
+			got.PhoneNumber != w.phone || !closeTo(got.Distance, w.distance) {
+			t.Errorf("location %d = %+v, want %+v", i, got, w)
+		}
+		if !got.hasCoordinates() {
+			t.Errorf("location %d has no coordinates", i)
+		}
+	}
+}
+
+func TestTacoBellLocatorComputesMissingDistance(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+
+	locator := &TacoBellLocator{BaseURL: u.URL}
+	locations, err := locator.Locate(context.Background(), testLat, testLng, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 3 {
+		t.Fatalf("got %d locations, want 3", len(locations))
+	}
+	santaAna := locations[2]
+	want := haversineDistance(testLat, testLng, 33.7592, -117.8407)
+	if santaAna.StoreID != "004012" || !closeTo(santaAna.Distance, want) {
+		t.Errorf("Santa Ana store = %+v, want distance %v", santaAna, want)
+	}
+}
+
+func TestOverpassLocator(t *testing.T) {
+	u := newUpstream(t)
+	var query string
+	handler := fixture(t, "overpass.json")
+	u.handle("GET /api/interpreter", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("data")
+		handler(w, r)
+	})
+
+	locator := &OverpassLocator{Endpoint: u.URL + "/api/interpreter"}
+	locations, err := locator.Locate(context.Background(), testLat, testLng, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bounding boxes are south, west, north, east
+	if !strings.Contains(query, "(33.594477,-117.916595,33.774657,-117.736415)") {
+		t.Errorf("query has the wrong bounding box:\n%s", query)
+	}
+
+	want := []struct {
+		placeID, name, address string
+		lat, lng               float64
+	}{
+		{"osm-node-5123456789", "Taco Bell", "4647 Barranca Parkway, Irvine, CA 92604", 33.6869412, -117.8075731},
+		{"osm-way-987654321", "Taco Bell Cantina", "Tustin", 33.7011, -117.8522},
+		{"osm-node-6200000001", "Taco Bell", "Address unknown", 33.6501, -117.8390},
+	}
+	if len(locations) != len(want) {
+		t.Fatalf("got %d locations, want %d", len(locations), len(want))
+	}
+	for i, w := range want {
+		got := locations[i]
+		if got.PlaceID != w.placeID || got.StoreID != w.placeID || got.Name != w.name || got.Address != w.address ||
+			!closeTo(got.Latitude, w.lat) || !closeTo(got.Longitude, w.lng) {
+			t.Errorf("location %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestStoreLocatorFallback(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	})
+	u.handle("GET /api/interpreter", fixture(t, "overpass.json"))
+
+	f := newTestFinder(u, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	locations, err := f.findTacoBellLocations(context.Background(), testLat, testLng, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 3 || locations[0].Source != "overpass" {
+		t.Errorf("got %+v, want the three Overpass locations", locations)
+	}
+	if n := u.count("/tacobellwebservices/"); n != 2 {
+		t.Errorf("%d requests to the store API, want 2", n)
+	}
+}
+
+func TestMatchOfficialStore(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", storesByLatitude(t, map[string]string{
+		"33.686941": "tacobell_stores_barranca.json",
+		"33.650100": "tacobell_stores.json",
+	}))
+	locator := &TacoBellLocator{BaseURL: u.URL}
+
+	barranca := TacoBellLocation{
+		PlaceID:   "osm-node-5123456789",
+		Address:   "4647 Barranca Parkway, Irvine, CA 92604",
+		Latitude:  33.6869412,
+		Longitude: -117.8075731,
+	}
+	storeNumber, err := matchOfficialStore(context.Background(), locator, barranca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storeNumber != "031447" {
+		t.Errorf("matchOfficialStore = %q, want 031447", storeNumber)
+	}
+
+	// Nothing official within reach of this one
+	lonely := TacoBellLocation{PlaceID: "osm-node-6200000001", Address: "Address unknown", Latitude: 33.6501, Longitude: -117.8390}
+	storeNumber, err = matchOfficialStore(context.Background(), locator, lonely)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storeNumber != "" {
+		t.Errorf("matchOfficialStore = %q, want no match", storeNumber)
+	}
+}
+
+func TestSameRestaurant(t *testing.T) {
+	first := TacoBellLocation{StoreID: "osm-node-1", Address: "1500 Main St, Santa Ana, CA 92701", Latitude: 33.7550, Longitude: -117.8670}
+	tests := []struct {
+		name  string
+		other TacoBellLocation
+		want  bool
+	}{
+		{"same store number", TacoBellLocation{StoreID: "osm-node-1"}, true},
+		{"same spot", TacoBellLocation{Address: "Address unknown", Latitude: 33.7555, Longitude: -117.8672}, true},
+		{"same address nearby", TacoBellLocation{Address: "1500 Main Street, Santa Ana", Latitude: 33.7580, Longitude: -117.8670}, true},
+		{"same address without coordinates", TacoBellLocation{Address: "1500 Main Street, Santa Ana"}, true},
+		{"same street far away", TacoBellLocation{Address: "2200 Main St, Santa Ana, CA 92701", Latitude: 33.6966, Longitude: -117.8670}, false},
+		{"different house number", TacoBellLocation{Address: "2200 Main St, Santa Ana, CA 92701"}, false},
+	}
+	for _, tt := range tests {
+		if got := sameRestaurant(first, tt.other); got != tt.want {
+			t.Errorf("%s: sameRestaurant = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fixedLocator returns the same locations for every search
+type fixedLocator struct {
+	name      string
+	locations []TacoBellLocation
+}
+
+func (l fixedLocator) Name() string { return l.name }
+
+func (l fixedLocator) Locate(ctx context.Context, lat, lng float64, radius int) ([]TacoBellLocation, error) {
+	return append([]TacoBellLocation{}, l.locations...), nil
+}
+
+func TestMergedLocators(t *testing.T) {
+	official := fixedLocator{name: "official", locations: []TacoBellLocation{
+		{StoreID: "031447", Address: "1 Glen Bell Way, Irvine, CA", Latitude: 33.6562, Longitude: -117.7431},
+		{StoreID: "018678", Address: "2 Main St, Irvine, CA", Latitude: 33.68, Longitude: -117.8, PhoneNumber: "(949) 555-0101"},
+	}}
+	osm := fixedLocator{name: "osm", locations: []TacoBellLocation{
+		{StoreID: "osm-node-1", Address: "1 Glen Bell Way, Irvine, CA", Latitude: 33.6563, Longitude: -117.7432, PhoneNumber: "(949) 555-0100"},
+		{StoreID: "osm-node-2", Address: "9 Far Rd, Tustin, CA", Latitude: 33.74, Longitude: -117.82},
+	}}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"first success wins", nil, []string{"031447 official", "018678 official"}},
+		{"merged", []Option{WithMergedLocators()}, []string{"031447 official", "018678 official", "osm-node-2 osm"}},
+	}
+	for _, tt := range tests {
+		f := NewChilitoBurritoFinder(append([]Option{WithStoreLocators(official, osm)}, tt.opts...)...)
+		locations, err := f.findTacoBellLocations(context.Background(), testLat, testLng, 10000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, location := range locations {
+			got = append(got, location.StoreID+" "+location.Source)
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		// The duplicate's phone number fills the gap in the official record
+		if tt.name == "merged" && locations[0].PhoneNumber != "(949) 555-0100" {
+			t.Errorf("merged: phone = %q, want the OpenStreetMap one", locations[0].PhoneNumber)
+		}
+	}
+}
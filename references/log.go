@@ -0,0 +1,28 @@
+package finder
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// discardLogger is used when no logger was configured
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type loggerKey struct{}
+
+// withLogger returns a context carrying logger. The finder attaches its
+// logger to the context of every search so that geocoders, locators and
+// menu checkers log through it without needing a logger of their own.
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFrom returns the logger carried by ctx, or a logger that discards
+// everything
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return discardLogger
+}
@@ -0,0 +1,52 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestSearchLogging(t *testing.T) {
+	u := searchUpstream(t)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := newTestFinder(u, WithLogger(logger)).FindChilitoBurritos("1 Glen Bell Way, Irvine, CA", 100000, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Store results are logged at Info with the store's attributes, and
+	// providers log through the search's context
+	found := map[string]bool{}
+	providerDebug := false
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		if record["msg"] == "item found" && record["level"] == "INFO" {
+			found[record["store_id"].(string)] = true
+		}
+		if record["level"] == "DEBUG" && record["provider"] != nil {
+			providerDebug = true
+		}
+	}
+	if !found["018678"] || !found["004012"] || len(found) != 2 {
+		t.Errorf("item found logged for %v, want 018678 and 004012", found)
+	}
+	if !providerDebug {
+		t.Error("no debug records from the providers")
+	}
+}
+
+func TestLoggerFromDefaultsToDiscard(t *testing.T) {
+	if loggerFrom(context.Background()) != discardLogger {
+		t.Error("loggerFrom without a logger did not return discardLogger")
+	}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if loggerFrom(withLogger(context.Background(), logger)) != logger {
+		t.Error("loggerFrom did not return the context's logger")
+	}
+}
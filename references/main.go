@@ -1,67 +1,336 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/yourusername/chilito/finder"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "cache":
+			runCache(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "menu":
+			runMenu(os.Args[2:])
+			return
+		}
+	}
+	runSearch(os.Args[1:])
+}
+
+// commonFlags are the logging and finder settings shared by every command
+type commonFlags struct {
+	fs            *flag.FlagSet
+	config        string
+	item          string
+	threshold     float64
+	verbose       bool
+	quiet         bool
+	logFormat     string
+	debugDelay    int
+	rateLimits    string
+	geocoders     string
+	nominatimURL  string
+	locators      string
+	mergeLocators bool
+	parallel      int
+	perHost       int
+	timeout       time.Duration
+	cache         cacheFlags
+	noCache       bool
+	refresh       bool
+	record        string
+	replay        string
+}
+
+// register defines the shared flags on fs
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	c.fs = fs
+	fs.StringVar(&c.config, "config", "", "Config file (default: the user config directory's chilito/config.yaml, if present)")
+	fs.Float64Var(&c.threshold, "threshold", finder.DefaultMatchThreshold, "Lowest match confidence (0-1) that counts as the item being on the menu")
+	fs.StringVar(&c.item, "item", "", "Menu item to look for: an item defined in the config file, or any menu name (default: the config file's item, the Chili Cheese Burrito)")
+	fs.BoolVar(&c.verbose, "verbose", false, "Enable verbose output")
+	fs.BoolVar(&c.quiet, "quiet", false, "Only log warnings and errors")
+	fs.StringVar(&c.logFormat, "log-format", "text", "Log format on stderr: text or json")
+	fs.IntVar(&c.debugDelay, "delay", 0, "Wait at least this many seconds between requests to the same host")
+	fs.StringVar(&c.rateLimits, "rate-limit", "", "Comma-separated per-host rate limits as host=interval[/burst], e.g. nominatim.openstreetmap.org=2s")
+	fs.StringVar(&c.geocoders, "geocoders", "tacobell,mapbox,nominatim", "Comma-separated geocoding providers to try, in order")
+	fs.StringVar(&c.nominatimURL, "nominatim-url", "", "Search endpoint of a self-hosted Nominatim instance")
+	fs.StringVar(&c.locators, "locators", "tacobell,overpass", "Comma-separated store locators to try, in order")
+	fs.BoolVar(&c.mergeLocators, "merge-locators", false, "Run every store locator and merge their results")
+	fs.IntVar(&c.parallel, "parallel", finder.DefaultParallelism, "Number of stores to check at once")
+	fs.IntVar(&c.perHost, "per-host", finder.DefaultPerHostLimit, "Maximum concurrent requests to any single host")
+	fs.DurationVar(&c.timeout, "timeout", finder.DefaultTimeouts.Request, "Give up on a single HTTP request after this long")
+	c.cache.register(fs)
+	fs.BoolVar(&c.noCache, "no-cache", false, "Neither read nor write the on-disk caches")
+	fs.BoolVar(&c.refresh, "refresh", false, "Ignore cached entries but store fresh results")
+	fs.StringVar(&c.record, "record", "", "Save every HTTP response to this directory (implies -no-cache)")
+	fs.StringVar(&c.replay, "replay", "", "Answer HTTP requests from a -record directory instead of the network (implies -no-cache)")
+}
+
+// setup installs the logger and creates the finder described by the config
+// file and the flags. Flags given on the command line override the config
+// file. Invalid flag values and config files are fatal.
+func (c *commonFlags) setup() (*finder.ChilitoBurritoFinder, *slog.Logger) {
+	// Results go to stdout; logging and progress go to stderr
+	logger, err := newLogger(c.logFormat, c.verbose, c.quiet)
+	if err != nil {
+		log.Fatalf("Invalid -log-format: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	cfg, path, err := loadConfig(c.config)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if path != "" {
+		logger.Debug("loaded config", "path", path)
+	}
+	set := make(map[string]bool)
+	c.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if c.nominatimURL != "" {
+		cfg.Endpoints.Nominatim = c.nominatimURL
+	}
+	if c.item != "" {
+		cfg.Item = c.item
+	}
+	if set["threshold"] {
+		cfg.Menu.MatchThreshold = c.threshold
+	}
+	if set["parallel"] {
+		cfg.Parallelism = c.parallel
+	}
+	if set["per-host"] {
+		cfg.PerHostLimit = c.perHost
+	}
+	if set["timeout"] {
+		cfg.Timeouts.Request = finder.Duration(c.timeout)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+
+	// Build the geocoder and store locator chains from the command line
+	opts := []finder.Option{finder.WithLogger(logger), finder.WithConfig(cfg)}
+	geocoderChain, err := parseProviders(c.geocoders, finder.DefaultGeocoders(nil))
+	if err != nil {
+		log.Fatalf("Invalid -geocoders: %v", err)
+	}
+	opts = append(opts, finder.WithGeocoderOrder(geocoderChain...))
+
+	locatorChain, err := parseProviders(c.locators, finder.DefaultStoreLocators(nil))
+	if err != nil {
+		log.Fatalf("Invalid -locators: %v", err)
+	}
+	opts = append(opts, finder.WithStoreLocatorOrder(locatorChain...))
+	if c.mergeLocators {
+		opts = append(opts, finder.WithMergedLocators())
+	}
+
+	// Recorded traffic is only complete if nothing is answered from the caches
+	switch {
+	case c.record != "" && c.replay != "":
+		log.Fatal("-record and -replay cannot be used together")
+	case c.record != "":
+		opts = append(opts, finder.WithTransport(finder.NewRecorder(c.record, nil)))
+		c.noCache = true
+	case c.replay != "":
+		replayer, err := finder.NewReplayer(c.replay)
+		if err != nil {
+			log.Fatalf("Invalid -replay: %v", err)
+		}
+		opts = append(opts, finder.WithTransport(replayer))
+		c.noCache = true
+	}
+
+	if !c.noCache {
+		opts = append(opts,
+			finder.WithGeocodeCache(c.cache.geocodeCache()),
+			finder.WithMenuCache(c.cache.menuCache()),
+			finder.WithStoreIDCache(c.cache.storeIDCache()))
+		if c.refresh {
+			opts = append(opts, finder.WithCacheRefresh())
+		}
+	}
+
+	limits, err := parseRateLimits(c.rateLimits)
+	if err != nil {
+		log.Fatalf("Invalid -rate-limit: %v", err)
+	}
+	for host, limit := range limits {
+		opts = append(opts, finder.WithRateLimit(host, limit))
+	}
+	if c.debugDelay > 0 {
+		logger.Info("request delay set", "seconds", c.debugDelay)
+		opts = append(opts, finder.WithRequestDelay(time.Duration(c.debugDelay)*time.Second))
+	}
+
+	// Create the finder (simplified to remove OAuth and API key options)
+	return finder.NewChilitoBurritoFinder(opts...), logger
+}
+
+// runSearch implements the default command: find the item near an address
+func runSearch(args []string) {
+	fs := flag.NewFlagSet("chilito", flag.ExitOnError)
+	var common commonFlags
 	var address string
 	var radius int
-	var verbose bool
-	var debugDelay int
+	var all bool
+	var limit int
+	var format string
 
-	flag.StringVar(&address, "address", "", "Address to search from (required)")
-	flag.IntVar(&radius, "radius", 100000, "Search radius in meters (default 100km)")
-	flag.BoolVar(&verbose, "verbose", false, "Enable verbose output")
-	flag.IntVar(&debugDelay, "delay", 0, "Add delay between API calls in seconds (for debugging)")
-	flag.Parse()
+	fs.StringVar(&address, "address", "", "Address to search from (required)")
+	fs.IntVar(&radius, "radius", 100000, "Search radius in meters (default 100km)")
+	common.register(fs)
+	fs.BoolVar(&all, "all", false, "List every store in the radius that has the item, not just the nearest")
+	fs.IntVar(&limit, "limit", 0, "Stop after this many stores with the item are found (implies -all)")
+	fs.StringVar(&format, "format", "text", "Output format: text, json, ndjson, csv or geojson")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  chilito -address ADDRESS [flags]\n  chilito serve [flags]\n  chilito cache list|prune|clear [flags]\n  chilito config show [flags]\n  chilito menu -store STORE [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
 
 	if address == "" {
-		flag.Usage()
+		fs.Usage()
 		return
 	}
-
-	// Set up logging based on verbosity
-	if verbose {
-		fmt.Println("Verbose mode enabled")
-	} else {
-		// Reduce log output in normal mode
-		log.SetOutput(os.Stderr)
+	if !outputFormats[format] {
+		log.Fatalf("Invalid -format %q: must be text, json, ndjson, csv or geojson", format)
 	}
 
-	fmt.Printf("Searching for Chili Cheese Burrito near: %s (within %d meters)\n", address, radius)
+	chilitoFinder, logger := common.setup()
+	item := chilitoFinder.Item().Name
+	logger.Info("searching for "+item, "address", address, "radius_m", radius)
 
-	// Create the finder (simplified to remove OAuth and API key options)
-	chilitoFinder := finder.NewChilitoBurritoFinder()
+	// Ctrl-C cancels in-flight requests; a second Ctrl-C kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	// If debug delay is set, display a message
-	if debugDelay > 0 {
-		fmt.Printf("Debug delay is set to %d seconds between API calls\n", debugDelay)
+	// The nearest store is just the first confirmed one
+	if !all && limit <= 0 {
+		limit = 1
 	}
 
 	startTime := time.Now()
-	result, err := chilitoFinder.FindNearestChilitoBurrito(address, radius)
+	results, err := chilitoFinder.FindChilitoBurritosContext(ctx, address, radius, limit)
 	searchDuration := time.Since(startTime)
+	stop()
 
-	if err != nil {
-		log.Fatalf("Error finding Chilito burrito: %v", err)
+	var interrupted *finder.SearchInterruptedError
+	if err != nil && !errors.As(err, &interrupted) {
+		log.Fatalf("Error finding %s: %v", item, err)
 	}
 
-	fmt.Printf("\nSearch completed in %v\n", searchDuration.Round(time.Second))
-
-	if result != nil {
-		fmt.Printf("\nSUCCESS! Found Chilito Burrito at: %s\n", result.Name)
-		fmt.Printf("Address: %s\n", result.Address)
-		fmt.Printf("Distance: %.2f km\n", result.Distance)
-		fmt.Printf("Phone: %s\n", result.PhoneNumber)
+	if format != "text" {
+		report := newSearchReport(item, address, radius, interrupted != nil, results)
+		if err := writeResults(os.Stdout, format, report); err != nil {
+			log.Fatalf("Error writing results: %v", err)
+		}
+	} else if interrupted != nil {
+		writeInterrupted(os.Stdout, item, interrupted, searchDuration)
 	} else {
-		fmt.Println("\nNo Taco Bell locations with Chilito Burrito found within the search radius.")
-		fmt.Println("Try increasing the search radius or using a different starting address.")
+		logger.Info("search completed", "duration", searchDuration.Round(time.Second))
+		writeText(os.Stdout, item, results, limit != 1)
+	}
+
+	if interrupted != nil {
+		os.Exit(130)
+	}
+}
+
+// newLogger builds the stderr logger for the -log-format, -verbose and
+// -quiet flags
+func newLogger(format string, verbose, quiet bool) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	switch {
+	case verbose:
+		level = slog.LevelDebug
+	case quiet:
+		level = slog.LevelWarn
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+// parseProviders splits a comma-separated provider list and checks every
+// name against the built-in providers
+func parseProviders[T interface{ Name() string }](list string, builtin []T) ([]string, error) {
+	known := make(map[string]bool)
+	for _, p := range builtin {
+		known[p.Name()] = true
+	}
+
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown provider %q", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one provider is required")
+	}
+	return names, nil
+}
+
+// parseRateLimits parses the -rate-limit flag: comma-separated
+// host=interval[/burst] entries
+func parseRateLimits(list string) (map[string]finder.RateLimit, error) {
+	limits := make(map[string]finder.RateLimit)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, spec, ok := strings.Cut(entry, "=")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("%q is not host=interval[/burst]", entry)
+		}
+		interval, burst, hasBurst := strings.Cut(spec, "/")
+		limit := finder.RateLimit{Burst: 1}
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid interval for %s: %q", host, interval)
+		}
+		limit.Interval = d
+		if hasBurst {
+			n, err := strconv.Atoi(burst)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid burst for %s: %q", host, burst)
+			}
+			limit.Burst = n
+		}
+		limits[host] = limit
 	}
+	return limits, nil
 }
@@ -0,0 +1,182 @@
+package finder
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultMatchThreshold is the lowest confidence reported as a match
+const DefaultMatchThreshold = 0.7
+
+// Scores for the ways a search term can occur in a menu item name, before
+// the coverage adjustment
+const (
+	// The term's words appear together and in order
+	phraseScore = 1.0
+	// The term's words appear in order within proximityWindow extra words
+	orderedScore = 0.85
+	// The term's words appear within proximityWindow extra words, in any order
+	unorderedScore = 0.7
+	// How many other words may sit between the words of a term
+	proximityWindow = 2
+	// Single-word terms shorter than this are abbreviations such as "ccb"
+	// and only count fully when they are the whole name
+	minWordLength = 4
+)
+
+// Match is the best match of an item among menu item names
+type Match struct {
+	// Term is the search term that matched
+	Term string
+	// Text is the menu item name it matched, with whitespace collapsed
+	Text string
+	// Confidence is between 0 (no match) and 1 (the name is the term)
+	Confidence float64
+}
+
+// Matcher scores menu item names against an item's search terms. Terms
+// only match whole words, and words of multi-word terms must be close to
+// each other. Names containing an exclusion term around the match do not
+// match at all.
+type Matcher struct {
+	terms   [][]string
+	exclude [][]string
+}
+
+// NewMatcher creates a matcher for the given search and exclusion terms
+func NewMatcher(terms, exclude []string) *Matcher {
+	m := &Matcher{}
+	for _, term := range lowerUnique(terms) {
+		if words := tokenize(term); len(words) > 0 {
+			m.terms = append(m.terms, words)
+		}
+	}
+	for _, term := range lowerUnique(exclude) {
+		if words := tokenize(term); len(words) > 0 {
+			m.exclude = append(m.exclude, words)
+		}
+	}
+	return m
+}
+
+// Score returns the best-scoring term for name and its confidence
+func (m *Matcher) Score(name string) Match {
+	words := tokenize(name)
+	excluded := m.excludedWords(words)
+	best := Match{Text: strings.Join(strings.Fields(name), " ")}
+	for _, term := range m.terms {
+		if score := scoreTerm(term, words, excluded); score > best.Confidence {
+			best.Term, best.Confidence = strings.Join(term, " "), score
+		}
+	}
+	return best
+}
+
+// excludedWords marks the words of name that are part of an exclusion term
+func (m *Matcher) excludedWords(words []string) []bool {
+	excluded := make([]bool, len(words))
+	for _, ex := range m.exclude {
+		for i := 0; i+len(ex) <= len(words); i++ {
+			if equalWords(words[i:i+len(ex)], ex) {
+				for j := i; j < i+len(ex); j++ {
+					excluded[j] = true
+				}
+			}
+		}
+	}
+	return excluded
+}
+
+// scoreTerm scores the closest non-excluded occurrence of term in words
+func scoreTerm(term, words []string, excluded []bool) float64 {
+	base := 0.0
+	for start := range words {
+		for end := start + len(term); end <= len(words) && end-start <= len(term)+proximityWindow; end++ {
+			if anyTrue(excluded[start:end]) {
+				break
+			}
+			window := words[start:end]
+			var score float64
+			switch {
+			case end-start == len(term) && equalWords(window, term):
+				score = phraseScore
+			case containsInOrder(window, term):
+				score = orderedScore
+			case containsAll(window, term):
+				score = unorderedScore
+			}
+			// Only windows that start and end on a word of the term count
+			if score > base && isIn(window[0], term) && isIn(window[len(window)-1], term) {
+				base = score
+			}
+		}
+	}
+	if base == 0 {
+		return 0
+	}
+
+	// Names that are mostly the term are more likely to be the item itself
+	score := base * (0.75 + 0.25*float64(len(term))/float64(len(words)))
+	if len(term) == 1 && len(term[0]) < minWordLength && len(words) > 1 {
+		score /= 2
+	}
+	return score
+}
+
+// tokenize lowercases s and splits it into words of letters and digits
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// containsInOrder reports whether term is a subsequence of window
+func containsInOrder(window, term []string) bool {
+	i := 0
+	for _, word := range window {
+		if i < len(term) && word == term[i] {
+			i++
+		}
+	}
+	return i == len(term)
+}
+
+// containsAll reports whether every word of term is in window
+func containsAll(window, term []string) bool {
+	for _, word := range term {
+		if !isIn(word, window) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIn(word string, words []string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+func anyTrue(values []bool) bool {
+	for _, v := range values {
+		if v {
+			return true
+		}
+	}
+	return false
+}
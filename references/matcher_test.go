@@ -0,0 +1,40 @@
+package finder
+
+import "testing"
+
+func TestMatcherScore(t *testing.T) {
+	m := NewMatcher(ChiliCheeseBurrito.SearchTerms(), []string{"chili cheese burrito sauce"})
+	tests := []struct {
+		name  string
+		found bool
+	}{
+		{"Chili Cheese Burrito", true},
+		{"CHILI CHEESE BURRITO®", true},
+		{"Chilito", true},
+		{"Chili Cheese Burrito - Limited Time", true},
+		{"Burrito, Chili Cheese", true},
+		{"Chili Beef Burrito", true},
+		{"CCB", true},
+		{"Chili Cheese Burrito Sauce", false},
+		{"Chili Cheese Fries and a Bean Burrito", false},
+		{"Beefy 5-Layer Burrito", false},
+		{"chilitos and burritos", false},
+		{"Get the CCB combo with a drink and fries", false},
+		{"accbar", false},
+	}
+	for _, tt := range tests {
+		match := m.Score(tt.name)
+		if found := match.Confidence >= DefaultMatchThreshold; found != tt.found {
+			t.Errorf("Score(%q) = %.2f for %q, want found = %v", tt.name, match.Confidence, match.Term, tt.found)
+		}
+	}
+}
+
+func TestMatcherPrefersExactNames(t *testing.T) {
+	m := NewMatcher([]string{"mexican pizza"}, nil)
+	exact := m.Score("Mexican Pizza")
+	longer := m.Score("Mexican Pizza Party Pack")
+	if exact.Confidence != 1 || longer.Confidence >= exact.Confidence {
+		t.Errorf("exact = %.2f, longer = %.2f; want 1 and less", exact.Confidence, longer.Confidence)
+	}
+}
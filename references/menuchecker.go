@@ -0,0 +1,424 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// MenuChecker decides whether a store sells a menu item
+type MenuChecker interface {
+	// Name identifies the checker (e.g. "scrape") for logging
+	Name() string
+	// CheckMenu reports whether the store sells the item. A location is
+	// expected to carry a resolved StoreID. Failures are reported as a
+	// result with StatusError rather than a separate error.
+	CheckMenu(ctx context.Context, location TacoBellLocation) CheckResult
+}
+
+// DefaultSearchTerms are the names that indicate the Chilito/Chili Cheese Burrito
+var DefaultSearchTerms = []string{
+	"chili cheese burrito",
+	"chilito burrito",
+	"chilito",
+	"chili burrito",
+	"ccb", // sometimes used as abbreviation
+}
+
+// DefaultMenuSelectors match the elements that hold product names on menu pages
+var DefaultMenuSelectors = []string{
+	".product-name",
+	".product-title",
+	".menu-item",
+	".food-item-name",
+}
+
+// DefaultMenuPaths are the menu pages checked for each store
+var DefaultMenuPaths = []string{
+	"/food/menu",
+	"/food/burritos",
+	"/food/specialties",
+	"/food/specialty",
+}
+
+// DefaultKnownChilitoStores lists store IDs known to sell the Chilito
+var DefaultKnownChilitoStores = []string{
+	"018678", // From your test case
+	// Add more known locations here
+}
+
+// DefaultScrapeUserAgents are browser-like User-Agents rotated between menu
+// page requests
+var DefaultScrapeUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:89.0) Gecko/20100101 Firefox/89.0",
+}
+
+// DefaultMenuChecker returns the standard checker for the Chili Cheese
+// Burrito: scrape the store's menu pages, then fall back to the list of
+// known Chilito locations
+func DefaultMenuChecker(client *http.Client) MenuChecker {
+	return DefaultConfig().menuChecker(client, ChiliCheeseBurrito)
+}
+
+// NewItemMenuChecker returns the standard checker for item, with the menu
+// settings of DefaultConfig
+func NewItemMenuChecker(client *http.Client, item Item) MenuChecker {
+	return DefaultConfig().menuChecker(client, item)
+}
+
+// ScrapingMenuChecker searches a store's menu pages on tacobell.com
+type ScrapingMenuChecker struct {
+	Client *http.Client
+	// SearchTerms are matched case-insensitively (defaults to DefaultSearchTerms)
+	SearchTerms []string
+	// Exclude lists terms whose occurrences do not count as a match, even
+	// though they contain a search term
+	Exclude []string
+	// Threshold is the lowest confidence reported as found (defaults to
+	// DefaultMatchThreshold)
+	Threshold float64
+	// Selectors locate product names on the page (defaults to DefaultMenuSelectors)
+	Selectors []string
+	// Paths are the menu pages to check (defaults to DefaultMenuPaths)
+	Paths []string
+	// Regions locate the store's menu on a page, in order of preference
+	// (defaults to DefaultMenuRegionSelectors)
+	Regions []string
+	// Chrome matches page parts removed before matching (defaults to
+	// DefaultChromeSelectors)
+	Chrome []string
+	// UnavailablePhrases in a listed item's product card mark it as not
+	// available at the store (defaults to DefaultUnavailablePhrases)
+	UnavailablePhrases []string
+	// UnavailableSelectors match elements of a product card, such as a
+	// disabled order button, that mark the item as not available
+	// (defaults to DefaultUnavailableSelectors)
+	UnavailableSelectors []string
+	// BaseURL is the website's base URL (defaults to DefaultTacoBellURL)
+	BaseURL string
+	// UserAgents are rotated between requests (defaults to
+	// DefaultScrapeUserAgents)
+	UserAgents []string
+}
+
+// Name implements MenuChecker
+func (c *ScrapingMenuChecker) Name() string { return "scrape" }
+
+// CheckMenu implements MenuChecker. Pages that embed their catalog as JSON
+// are checked against that data; others are scraped for item names. An
+// item that is listed but greyed out, sold out or flagged as unavailable is
+// reported as StatusNotAvailable unless another page offers it. It reports
+// StatusError only if none of the menu pages could be loaded.
+func (c *ScrapingMenuChecker) CheckMenu(ctx context.Context, location TacoBellLocation) CheckResult {
+	logger := loggerFrom(ctx).With("store_id", location.StoreID, "checker", c.Name())
+
+	matcher := NewMatcher(orDefault(c.SearchTerms, DefaultSearchTerms), c.Exclude)
+	threshold := c.Threshold
+	if threshold == 0 {
+		threshold = DefaultMatchThreshold
+	}
+	baseURL := stringOr(c.BaseURL, DefaultTacoBellURL)
+
+	loaded := 0
+	var best Match
+	var unavailable *CheckResult
+	for _, path := range orDefault(c.Paths, DefaultMenuPaths) {
+		menuURL := fmt.Sprintf("%s%s?store=%s", baseURL, path, location.StoreID)
+
+		htmlContent, err := c.fetch(ctx, menuURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return errorResult(location, c.Name(), ctx.Err())
+			}
+			logger.Warn("failed to load menu page", "url", menuURL, "error", err)
+			continue
+		}
+		loaded++
+		logger.Debug("loaded menu page", "url", menuURL, "bytes", len(htmlContent))
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+		if err != nil {
+			logger.Warn("failed to parse menu page", "url", menuURL, "error", err)
+			continue
+		}
+
+		result, weak := c.checkPage(logger, doc, location, menuURL, matcher, threshold)
+		switch result.Status {
+		case StatusFound:
+			return result
+		case StatusNotAvailable:
+			// Another page may still offer it, e.g. as a specialty
+			if unavailable == nil {
+				unavailable = &result
+			}
+		}
+		if weak.Confidence > best.Confidence {
+			best = weak
+		}
+	}
+
+	if loaded == 0 {
+		return errorResult(location, c.Name(), fmt.Errorf("could not load any menu page for store %s", location.StoreID))
+	}
+	if unavailable != nil {
+		return *unavailable
+	}
+	return CheckResult{Location: location, Status: StatusNotFound, Checker: c.Name(), Confidence: best.Confidence}
+}
+
+// checkPage looks for the item on one menu page. It returns a result with
+// StatusFound or StatusNotAvailable if the page lists the item, and the
+// best match below the threshold otherwise.
+func (c *ScrapingMenuChecker) checkPage(logger *slog.Logger, doc *goquery.Document, location TacoBellLocation, menuURL string, matcher *Matcher, threshold float64) (CheckResult, Match) {
+	var found, unavailable *CheckResult
+	var weak Match
+	consider := func(match Match, name, unavailability string, item *MenuItem) {
+		switch {
+		case match.Confidence < threshold:
+			if match.Confidence > weak.Confidence {
+				weak = match
+			}
+		case unavailability != "":
+			if unavailable == nil || match.Confidence > unavailable.Confidence {
+				result := c.result(location, StatusNotAvailable, menuURL, match)
+				result.Evidence = name + ": " + unavailability
+				result.MenuItem = item
+				unavailable = &result
+			}
+		default:
+			if found == nil || match.Confidence > found.Confidence {
+				result := c.result(location, StatusFound, menuURL, match)
+				if item != nil {
+					result.Evidence = "menu data lists " + name
+				}
+				result.MenuItem = item
+				found = &result
+			}
+		}
+	}
+
+	// Structured data says exactly what the store sells, so the page's
+	// text is only looked at when there is none
+	if items, ok := ParseMenuData(doc); ok {
+		logger.Debug("found menu data", "url", menuURL, "items", len(items))
+		for i := range items {
+			item := &items[i]
+			unavailability := ""
+			if !item.Available {
+				unavailability = "marked unavailable in the menu data"
+			}
+			consider(matcher.Score(item.Name), item.String(), unavailability, item)
+		}
+	} else {
+		// Score the item names in the store's menu rather than the raw
+		// HTML, so that scripts, navigation and promos cannot produce a match
+		region := menuRegion(doc, orDefault(c.Regions, DefaultMenuRegionSelectors), orDefault(c.Chrome, DefaultChromeSelectors))
+		selectors := strings.Join(orDefault(c.Selectors, DefaultMenuSelectors), ", ")
+		phrases := orDefault(c.UnavailablePhrases, DefaultUnavailablePhrases)
+		entries := menuEntries(region, selectors)
+		for i, entry := range entries {
+			match := matcher.Score(entry.name)
+			unavailability := ""
+			if match.Confidence >= threshold {
+				unavailability = unavailableSignal(productCard(entries, i, phrases), phrases,
+					orDefault(c.UnavailableSelectors, DefaultUnavailableSelectors))
+			}
+			consider(match, match.Text, unavailability, nil)
+		}
+	}
+
+	switch {
+	case found != nil:
+		logger.Debug("menu item matched", "url", menuURL, "term", found.MatchedTerm, "evidence", found.Evidence, "confidence", found.Confidence)
+		return *found, weak
+	case unavailable != nil:
+		logger.Debug("menu item unavailable", "url", menuURL, "term", unavailable.MatchedTerm, "evidence", unavailable.Evidence)
+		return *unavailable, weak
+	case weak.Confidence > 0:
+		logger.Debug("menu item below threshold", "url", menuURL, "term", weak.Term, "name", weak.Text, "confidence", weak.Confidence)
+	}
+	return CheckResult{Status: StatusNotFound}, weak
+}
+
+// result builds a result for a match on a menu page
+func (c *ScrapingMenuChecker) result(location TacoBellLocation, status CheckStatus, menuURL string, match Match) CheckResult {
+	evidence := match.Text
+	if i := strings.Index(strings.ToLower(evidence), strings.Fields(match.Term)[0]); i >= 0 {
+		evidence = snippet(evidence, i, i+len(match.Term))
+	}
+	return CheckResult{
+		Location:    location,
+		Status:      status,
+		Checker:     c.Name(),
+		MatchedURL:  menuURL,
+		MatchedTerm: match.Term,
+		Evidence:    evidence,
+		Confidence:  match.Confidence,
+	}
+}
+
+// fetch loads a menu page. Retries are left to the client's transport.
+func (c *ScrapingMenuChecker) fetch(ctx context.Context, menuURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", menuURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Set headers to mimic a browser
+	userAgents := orDefault(c.UserAgents, DefaultScrapeUserAgents)
+	req.Header.Set("User-Agent", userAgents[rand.Intn(len(userAgents))])
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Upgrade-Insecure-Requests", "1")
+	req.Header.Set("Cache-Control", "max-age=0")
+
+	resp, err := httpClient(c.Client).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("received status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// orDefault returns values, or defaults if values is empty
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
+// KnownLocationsChecker answers from a fixed set of store IDs known to sell
+// the item. Stores outside the set are reported as StatusUnknown, since
+// the list says nothing about them.
+type KnownLocationsChecker struct {
+	Stores map[string]bool
+}
+
+// NewKnownLocationsChecker creates a checker for the given store IDs
+func NewKnownLocationsChecker(storeIDs ...string) *KnownLocationsChecker {
+	stores := make(map[string]bool, len(storeIDs))
+	for _, id := range storeIDs {
+		stores[id] = true
+	}
+	return &KnownLocationsChecker{Stores: stores}
+}
+
+// Name implements MenuChecker
+func (c *KnownLocationsChecker) Name() string { return "known" }
+
+// CheckMenu implements MenuChecker
+func (c *KnownLocationsChecker) CheckMenu(ctx context.Context, location TacoBellLocation) CheckResult {
+	if c.Stores[location.StoreID] {
+		loggerFrom(ctx).Debug("store is a known location", "store_id", location.StoreID, "checker", c.Name())
+		return CheckResult{
+			Location:   location,
+			Status:     StatusFound,
+			Checker:    c.Name(),
+			Evidence:   "store " + location.StoreID + " is in the known locations list",
+			Confidence: 1,
+		}
+	}
+	return CheckResult{Location: location, Status: StatusUnknown, Checker: c.Name()}
+}
+
+// AnyOf returns a checker that asks each checker in turn and reports the
+// item as found as soon as one of them does. A checker that saw the item
+// listed as unavailable also ends the search, since that is first-hand
+// evidence about the store. Otherwise the most informative answer wins:
+// not found, then error, then unknown.
+func AnyOf(checkers ...MenuChecker) MenuChecker {
+	return &combinedChecker{checkers: checkers, any: true}
+}
+
+// AllOf returns a checker that reports the item as found only if every
+// checker does. It stops at the first checker that does not.
+func AllOf(checkers ...MenuChecker) MenuChecker {
+	return &combinedChecker{checkers: checkers}
+}
+
+// combinedChecker implements AnyOf and AllOf
+type combinedChecker struct {
+	checkers []MenuChecker
+	any      bool
+}
+
+// Name implements MenuChecker
+func (c *combinedChecker) Name() string {
+	names := make([]string, len(c.checkers))
+	for i, checker := range c.checkers {
+		names[i] = checker.Name()
+	}
+	op := "all"
+	if c.any {
+		op = "any"
+	}
+	return op + "(" + strings.Join(names, ",") + ")"
+}
+
+// anyPrecedence ranks non-found answers for AnyOf; higher is more informative
+var anyPrecedence = map[CheckStatus]int{
+	StatusUnknown:  0,
+	StatusError:    1,
+	StatusNotFound: 2,
+}
+
+// CheckMenu implements MenuChecker
+func (c *combinedChecker) CheckMenu(ctx context.Context, location TacoBellLocation) CheckResult {
+	if len(c.checkers) == 0 {
+		return errorResult(location, c.Name(), errors.New("no menu checkers configured"))
+	}
+
+	var best CheckResult
+	var errs []error
+	for i, checker := range c.checkers {
+		if err := ctx.Err(); err != nil {
+			return errorResult(location, c.Name(), err)
+		}
+
+		result := checker.CheckMenu(ctx, location)
+		if result.Err != nil {
+			result.Err = fmt.Errorf("%s: %w", checker.Name(), result.Err)
+			errs = append(errs, result.Err)
+		}
+
+		if !c.any {
+			if result.Status != StatusFound || i == len(c.checkers)-1 {
+				return result
+			}
+			continue
+		}
+
+		if result.Status == StatusFound || result.Status == StatusNotAvailable {
+			return result
+		}
+		if i == 0 || anyPrecedence[result.Status] > anyPrecedence[best.Status] {
+			best = result
+		}
+	}
+
+	// Report every failure if errors are the best we have
+	if best.Status == StatusError {
+		best.Err = errors.Join(errs...)
+	}
+	return best
+}
@@ -0,0 +1,222 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestScrapingMenuChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		status   CheckStatus
+		term     string
+		evidence string
+	}{
+		{"keyword", "menu/burritos_chilito.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+		{"selector", "menu/specialties_selector.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+		{"absent", "menu/burritos.html", StatusNotFound, "", ""},
+		{"only in chrome", "menu/burritos_chrome.html", StatusNotFound, "", ""},
+		{"only in chrome without main", "menu/specialties_no_main.html", StatusNotFound, "", ""},
+		{"in menu beside chrome", "menu/burritos_chrome_chilito.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+		{"promo in a carousel", "menu/burritos_carousel_promo.html", StatusNotFound, "", ""},
+		{"name in card header", "menu/burritos_card_header.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+		{"disabled button", "menu/burritos_disabled.html", StatusNotAvailable, "chili cheese burrito", "button[disabled]"},
+		{"unavailable note", "menu/specialties_unavailable.html", StatusNotAvailable, "chili cheese burrito", "not available at this location"},
+		{"another item sold out", "menu/burritos_other_sold_out.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+		{"another item sold out in plain text", "menu/burritos_text_sold_out.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+		{"another item sold out in a shared card", "menu/burritos_article_list.html", StatusFound, "chili cheese burrito", "Chili Cheese Burrito"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpstream(t)
+			u.handle("GET /food/burritos", fixture(t, tt.page))
+
+			checker := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos"}}
+			result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+			if result.Status != tt.status {
+				t.Fatalf("Status = %v, want %v (err %v)", result.Status, tt.status, result.Err)
+			}
+			if result.MatchedTerm != tt.term {
+				t.Errorf("MatchedTerm = %q, want %q", result.MatchedTerm, tt.term)
+			}
+			if !strings.Contains(result.Evidence, tt.evidence) {
+				t.Errorf("Evidence = %q, want it to contain %q", result.Evidence, tt.evidence)
+			}
+			if tt.status != StatusNotFound && result.MatchedURL != u.URL+"/food/burritos?store=031447" {
+				t.Errorf("MatchedURL = %q", result.MatchedURL)
+			}
+		})
+	}
+}
+
+func TestScrapingMenuCheckerSendsStore(t *testing.T) {
+	u := newUpstream(t)
+	var stores []string
+	handler := fixture(t, "menu/burritos.html")
+	u.handle("GET /food/{page}", func(w http.ResponseWriter, r *http.Request) {
+		stores = append(stores, r.URL.Query().Get("store"))
+		handler(w, r)
+	})
+
+	checker := &ScrapingMenuChecker{BaseURL: u.URL}
+	result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "018678"})
+	if result.Status != StatusNotFound {
+		t.Fatalf("Status = %v, want not_found", result.Status)
+	}
+	if len(stores) != len(DefaultMenuPaths) {
+		t.Fatalf("%d menu pages requested, want %d", len(stores), len(DefaultMenuPaths))
+	}
+	for _, store := range stores {
+		if store != "018678" {
+			t.Errorf("menu page requested for store %q", store)
+		}
+	}
+}
+
+func TestKnownLocationsChecker(t *testing.T) {
+	checker := NewKnownLocationsChecker("018678")
+
+	result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "018678"})
+	if result.Status != StatusFound || result.Checker != "known" {
+		t.Errorf("known store: %+v", result)
+	}
+	result = checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusUnknown {
+		t.Errorf("other store: Status = %v, want unknown", result.Status)
+	}
+}
+
+func TestDefaultCheckerFallsBackToKnownLocations(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos.html"))
+
+	checker := Config{Endpoints: u.endpoints()}.menuChecker(nil, ChiliCheeseBurrito)
+
+	// The menu does not list it, but the store is known to sell it
+	result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "018678"})
+	if result.Status != StatusFound || result.Checker != "known" {
+		t.Errorf("known store: Status = %v, Checker = %q; want found by known", result.Status, result.Checker)
+	}
+
+	// A miss from the scraper beats the known list's shrug
+	result = checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusNotFound || result.Checker != "scrape" {
+		t.Errorf("other store: Status = %v, Checker = %q; want not_found by scrape", result.Status, result.Checker)
+	}
+}
+
+func TestScrapingMenuCheckerIgnoresChrome(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/burritos", fixture(t, "menu/burritos_chrome.html"))
+
+	// Matching the whole page finds the item in the navigation; matching
+	// only the menu region does not
+	tests := []struct {
+		name    string
+		regions []string
+		chrome  []string
+		want    CheckStatus
+	}{
+		{"whole page", []string{"body"}, []string{"base"}, StatusFound},
+		{"menu region", nil, nil, StatusNotFound},
+	}
+	for _, tt := range tests {
+		checker := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos"}, Regions: tt.regions, Chrome: tt.chrome}
+		result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+		if result.Status != tt.want {
+			t.Errorf("%s: Status = %v (%q), want %v", tt.name, result.Status, result.Evidence, tt.want)
+		}
+	}
+}
+
+func TestScrapingMenuCheckerPrefersAvailablePages(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/burritos", fixture(t, "menu/burritos_disabled.html"))
+	u.handle("GET /food/specialties", fixture(t, "menu/specialties_selector.html"))
+	u.handle("GET /food/menu", fixture(t, "menu/burritos.html"))
+
+	checker := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos", "/food/menu"}}
+	result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusNotAvailable || !strings.HasSuffix(result.MatchedURL, "/food/burritos?store=031447") {
+		t.Errorf("greyed out only: Status = %v at %q, want not_available at the burritos page", result.Status, result.MatchedURL)
+	}
+
+	// Offered on another page, so it can be ordered after all
+	checker.Paths = []string{"/food/burritos", "/food/specialties"}
+	result = checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+	if result.Status != StatusFound || !strings.HasSuffix(result.MatchedURL, "/food/specialties?store=031447") {
+		t.Errorf("also offered: Status = %v at %q, want found at the specialties page", result.Status, result.MatchedURL)
+	}
+}
+
+func TestDefaultCheckerReportsUnavailableOverKnownLocations(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/{page}", fixture(t, "menu/burritos_disabled.html"))
+
+	// The store's own menu greys it out, which beats the known list
+	checker := Config{Endpoints: u.endpoints()}.menuChecker(nil, ChiliCheeseBurrito)
+	result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "018678"})
+	if result.Status != StatusNotAvailable || result.Checker != "scrape" {
+		t.Errorf("Status = %v, Checker = %q; want not_available by scrape", result.Status, result.Checker)
+	}
+}
+
+// stubChecker gives the same answer for every store and counts its calls
+type stubChecker struct {
+	name   string
+	status CheckStatus
+	calls  int
+}
+
+func (c *stubChecker) Name() string { return c.name }
+
+func (c *stubChecker) CheckMenu(ctx context.Context, location TacoBellLocation) CheckResult {
+	c.calls++
+	result := CheckResult{Location: location, Status: c.status, Checker: c.name}
+	if c.status == StatusError {
+		result.Err = errors.New("menu unavailable")
+	}
+	return result
+}
+
+func TestAllOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []CheckStatus
+		want     CheckStatus
+		checker  string
+		calls    int
+	}{
+		{"all agree", []CheckStatus{StatusFound, StatusFound, StatusFound}, StatusFound, "c", 3},
+		{"one not found", []CheckStatus{StatusFound, StatusNotFound, StatusFound}, StatusNotFound, "b", 2},
+		{"one error", []CheckStatus{StatusError, StatusFound, StatusFound}, StatusError, "a", 1},
+	}
+	for _, tt := range tests {
+		var checkers []MenuChecker
+		var stubs []*stubChecker
+		for i, status := range tt.statuses {
+			stub := &stubChecker{name: string(rune('a' + i)), status: status}
+			stubs = append(stubs, stub)
+			checkers = append(checkers, stub)
+		}
+
+		result := AllOf(checkers...).CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+		if result.Status != tt.want || result.Checker != tt.checker {
+			t.Errorf("%s: Status = %v from %q, want %v from %q", tt.name, result.Status, result.Checker, tt.want, tt.checker)
+		}
+		if tt.want == StatusError && (result.Err == nil || !strings.HasPrefix(result.Err.Error(), tt.checker+": ")) {
+			t.Errorf("%s: Err = %v, want it to name checker %q", tt.name, result.Err, tt.checker)
+		}
+		calls := 0
+		for _, stub := range stubs {
+			calls += stub.calls
+		}
+		if calls != tt.calls {
+			t.Errorf("%s: %d checkers asked, want %d", tt.name, calls, tt.calls)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+// menuFormats are the output formats accepted by "chilito menu -format"
+var menuFormats = map[string]bool{
+	"text": true,
+	"json": true,
+	"csv":  true,
+}
+
+// menuReport is the document emitted by "chilito menu -format json"
+type menuReport struct {
+	SchemaVersion int               `json:"schema_version"`
+	StoreID       string            `json:"store_id"`
+	Items         []finder.MenuItem `json:"items"`
+}
+
+// runMenu implements "chilito menu -store STORE"
+func runMenu(args []string) {
+	fs := flag.NewFlagSet("chilito menu", flag.ExitOnError)
+	var common commonFlags
+	var storeID string
+	var format string
+
+	fs.StringVar(&storeID, "store", "", "Official store number, e.g. 018678 (required)")
+	fs.StringVar(&format, "format", "text", "Output format: text, json or csv")
+	common.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage:\n  chilito menu -store STORE [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if storeID == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if !menuFormats[format] {
+		log.Fatalf("Invalid -format %q: must be text, json or csv", format)
+	}
+
+	chilitoFinder, logger := common.setup()
+	logger.Info("fetching menu", "store_id", storeID)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	items, err := chilitoFinder.FetchMenuContext(ctx, storeID)
+	if err != nil {
+		log.Fatalf("Error fetching menu: %v", err)
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(menuReport{SchemaVersion: schemaVersion, StoreID: storeID, Items: items})
+	case "csv":
+		err = writeMenuCSV(os.Stdout, items)
+	default:
+		err = writeMenuText(os.Stdout, storeID, items)
+	}
+	if err != nil {
+		log.Fatalf("Error writing menu: %v", err)
+	}
+}
+
+// writeMenuText prints the menu as a table
+func writeMenuText(w io.Writer, storeID string, items []finder.MenuItem) error {
+	fmt.Fprintf(w, "Menu of store %s (%d items):\n", storeID, len(items))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CATEGORY\tNAME\tPRICE\tCODE\tAVAILABLE")
+	for _, item := range items {
+		price := ""
+		if item.Price > 0 {
+			price = fmt.Sprintf("$%.2f", item.Price)
+		}
+		available := "yes"
+		if !item.Available {
+			available = "no"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", item.Category, item.Name, price, item.Code, available)
+	}
+	return tw.Flush()
+}
+
+// writeMenuCSV writes one row per item under a header row
+func writeMenuCSV(w io.Writer, items []finder.MenuItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"category", "name", "price", "code", "available"}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		price := ""
+		if item.Price > 0 {
+			price = formatFloat(item.Price)
+		}
+		row := []string{item.Category, item.Name, price, item.Code, strconv.FormatBool(item.Available)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
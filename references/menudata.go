@@ -0,0 +1,279 @@
+package finder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// MenuItem is a product from a menu page's embedded catalog data
+type MenuItem struct {
+	Name     string  `json:"name"`
+	Code     string  `json:"code,omitempty"`
+	Category string  `json:"category,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+	// Available is false when the data marks the item as sold out or not
+	// offered at the store
+	Available bool `json:"available"`
+}
+
+// String describes the item for evidence and logs
+func (i MenuItem) String() string {
+	var details []string
+	if i.Code != "" {
+		details = append(details, "code "+i.Code)
+	}
+	if i.Category != "" {
+		details = append(details, i.Category)
+	}
+	if i.Price > 0 {
+		details = append(details, fmt.Sprintf("$%.2f", i.Price))
+	}
+	if !i.Available {
+		details = append(details, "unavailable")
+	}
+	if len(details) == 0 {
+		return i.Name
+	}
+	return i.Name + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Global variables that server-rendered pages assign their state to
+var stateVariables = []string{
+	"__INITIAL_STATE__",
+	"__PRELOADED_STATE__",
+	"__APOLLO_STATE__",
+	"__NUXT__",
+}
+
+// schema.org types whose linked data lists a whole menu. Other linked data,
+// such as a Product snippet for search engines, says nothing about what the
+// store sells.
+var menuSchemaTypes = []string{"Menu", "MenuSection", "ItemList"}
+
+// menuDataBlob is the JSON text of a data script on a menu page
+type menuDataBlob struct {
+	text string
+	// linkedData is set for schema.org JSON-LD
+	linkedData bool
+}
+
+// ParseMenuData finds the store's menu data embedded in a menu page, such
+// as Next.js's __NEXT_DATA__, an inline state assignment or a schema.org
+// Menu, and returns the products in it. ok is false if the page has no
+// menu data with products.
+func ParseMenuData(doc *goquery.Document) (items []MenuItem, ok bool) {
+	for _, blob := range menuDataBlobs(doc) {
+		var data any
+		dec := json.NewDecoder(strings.NewReader(blob.text))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			continue
+		}
+		if blob.linkedData {
+			data = menuSchemas(data)
+		}
+		items = collectMenuItems(items, data, "")
+	}
+	return items, len(items) > 0
+}
+
+// menuDataBlobs returns the JSON text of every menu data script on the page
+func menuDataBlobs(doc *goquery.Document) []menuDataBlob {
+	var blobs []menuDataBlob
+	doc.Find("script").Each(func(i int, s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		switch {
+		case text == "":
+		case s.AttrOr("id", "") == "__NEXT_DATA__":
+			blobs = append(blobs, menuDataBlob{text: text})
+		case s.AttrOr("type", "") == "application/ld+json":
+			blobs = append(blobs, menuDataBlob{text: text, linkedData: true})
+		default:
+			for _, name := range stateVariables {
+				i := strings.Index(text, name)
+				if i < 0 {
+					continue
+				}
+				rest := text[i+len(name):]
+				if j := strings.Index(rest, "="); j >= 0 && strings.TrimSpace(rest[:j]) == "" {
+					// The decoder stops after the first value, so a
+					// trailing semicolon or further code is ignored
+					blobs = append(blobs, menuDataBlob{text: strings.TrimSpace(rest[j+1:])})
+					break
+				}
+			}
+		}
+	})
+	return blobs
+}
+
+// menuSchemas returns the objects in decoded JSON-LD whose @type is one of
+// menuSchemaTypes, looking inside lists and @graph but not inside matches
+func menuSchemas(v any) []any {
+	var menus []any
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			menus = append(menus, menuSchemas(elem)...)
+		}
+	case map[string]any:
+		types, ok := v["@type"].([]any)
+		if !ok {
+			types = []any{v["@type"]}
+		}
+		for _, typ := range types {
+			for _, menuType := range menuSchemaTypes {
+				if typ == menuType {
+					return []any{v}
+				}
+			}
+		}
+		menus = menuSchemas(v["@graph"])
+	}
+	return menus
+}
+
+// Keys holding the parts of a product, in order of preference
+var (
+	nameKeys = []string{"name", "productName", "displayName", "title"}
+	codeKeys = []string{"code", "productCode", "sku", "productId", "id"}
+	// productKeys identify a product on their own; "id" is too common
+	productKeys  = []string{"code", "productCode", "sku"}
+	categoryKeys = []string{"category", "categoryName"}
+	// categoryNameKeys name an object that lists products
+	categoryNameKeys = []string{"name", "categoryName", "displayName", "title"}
+	priceKeys        = []string{"price", "priceValue", "offers"}
+	availableKeys    = []string{"available", "isAvailable", "availableForPickup", "inStock", "purchasable"}
+)
+
+// collectMenuItems walks decoded JSON and appends every object that looks
+// like a product. category is the name of the nearest enclosing object
+// that lists products, which is taken as their category.
+func collectMenuItems(items []MenuItem, v any, category string) []MenuItem {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			items = collectMenuItems(items, elem, category)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		lists := false
+		for key, elem := range v {
+			keys = append(keys, key)
+			lists = lists || listsProducts(elem)
+		}
+
+		// Objects that list products are categories, not products
+		if !lists {
+			if item, ok := menuItemFrom(v, category); ok {
+				return append(items, item)
+			}
+		} else if name := firstString(v, categoryNameKeys); name != "" {
+			category = name
+		}
+		// Walk in a stable order so that results do not vary between runs
+		sort.Strings(keys)
+		for _, key := range keys {
+			items = collectMenuItems(items, v[key], category)
+		}
+	}
+	return items
+}
+
+// listsProducts reports whether v is a list with a product in it
+func listsProducts(v any) bool {
+	list, _ := v.([]any)
+	for _, elem := range list {
+		if obj, ok := elem.(map[string]any); ok {
+			if _, ok := menuItemFrom(obj, ""); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// menuItemFrom converts obj to a MenuItem if it has a name and a price or
+// product code
+func menuItemFrom(obj map[string]any, category string) (MenuItem, bool) {
+	item := MenuItem{
+		Name:      firstString(obj, nameKeys),
+		Code:      firstString(obj, codeKeys),
+		Category:  firstString(obj, categoryKeys),
+		Available: true,
+	}
+	price, hasPrice := firstPrice(obj)
+	if item.Name == "" || (!hasPrice && firstString(obj, productKeys) == "") {
+		return MenuItem{}, false
+	}
+	item.Price = price
+	if item.Category == "" {
+		item.Category = category
+	}
+	for _, key := range availableKeys {
+		if b, ok := obj[key].(bool); ok {
+			item.Available = b
+			break
+		}
+	}
+	if stock, ok := obj["stock"].(map[string]any); ok {
+		if status, _ := stock["stockLevelStatus"].(string); strings.EqualFold(status, "outOfStock") {
+			item.Available = false
+		}
+	}
+	return item, true
+}
+
+// firstString returns the first of keys whose value is a non-empty string
+// or number, or an object with a name
+func firstString(obj map[string]any, keys []string) string {
+	for _, key := range keys {
+		switch v := obj[key].(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case json.Number:
+			return v.String()
+		case map[string]any:
+			if name, ok := v["name"].(string); ok && name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// firstPrice returns the price of a product. Prices are numbers, strings
+// such as "$2.49", or objects with a value or price field.
+func firstPrice(obj map[string]any) (float64, bool) {
+	for _, key := range priceKeys {
+		if price, ok := parsePrice(obj[key]); ok {
+			return price, true
+		}
+	}
+	return 0, false
+}
+
+func parsePrice(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(v), "$"), 64)
+		return f, err == nil
+	case map[string]any:
+		for _, key := range []string{"value", "price", "amount"} {
+			if price, ok := parsePrice(v[key]); ok {
+				return price, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package finder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func parseFixture(t *testing.T, name string) []MenuItem {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, _ := ParseMenuData(doc)
+	return items
+}
+
+func TestParseMenuData(t *testing.T) {
+	tests := []struct {
+		page string
+		want []MenuItem
+	}{
+		{"menu/burritos_next_data.html", []MenuItem{
+			{Name: "Bean Burrito", Code: "22001", Category: "Burritos", Price: 1.99, Available: true},
+			{Name: "Chili Cheese Burrito", Code: "22948", Category: "Burritos", Price: 2.49, Available: true},
+			{Name: "Burrito Supreme®", Code: "22120", Category: "Burritos", Price: 4.99, Available: true},
+		}},
+		{"menu/specialties_state.html", []MenuItem{
+			{Name: "Mexican Pizza", Code: "30500", Category: "Specialties", Price: 5.49, Available: true},
+			{Name: "Crunchwrap Supreme", Code: "30510", Category: "Specialties", Price: 5.29, Available: false},
+		}},
+		{"menu/burritos_ld_menu.html", []MenuItem{
+			{Name: "Bean Burrito", Category: "Burritos", Price: 1.99, Available: true},
+			{Name: "Chili Cheese Burrito", Category: "Burritos", Price: 2.49, Available: true},
+		}},
+		// Product snippets for search engines are not the store's menu
+		{"menu/burritos_ld_product.html", nil},
+		{"menu/burritos.html", nil},
+	}
+	for _, tt := range tests {
+		if got := parseFixture(t, tt.page); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestScrapingMenuCheckerPrefersMenuData(t *testing.T) {
+	tests := []struct {
+		page   string
+		status CheckStatus
+		code   string
+	}{
+		// The rendered list is incomplete; the data lists the item
+		{"menu/burritos_next_data.html", StatusFound, "22948"},
+		// The rendered list is stale; the data says it is unavailable
+		{"menu/burritos_next_data_unavailable.html", StatusNotAvailable, "22948"},
+		// A search engine snippet for the item is not a menu listing
+		{"menu/burritos_ld_product.html", StatusNotFound, ""},
+		// A snippet for another product doesn't hide the rendered list
+		{"menu/burritos_ld_other_product.html", StatusFound, ""},
+	}
+	for _, tt := range tests {
+		u := newUpstream(t)
+		u.handle("GET /food/burritos", fixture(t, tt.page))
+
+		checker := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos"}}
+		result := checker.CheckMenu(context.Background(), TacoBellLocation{StoreID: "031447"})
+		if result.Status != tt.status {
+			t.Errorf("%s: Status = %v (%q), want %v", tt.page, result.Status, result.Evidence, tt.status)
+			continue
+		}
+		if tt.code != "" && (result.MenuItem == nil || result.MenuItem.Code != tt.code) {
+			t.Errorf("%s: MenuItem = %+v, want code %s", tt.page, result.MenuItem, tt.code)
+		}
+	}
+}
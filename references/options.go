@@ -0,0 +1,371 @@
+package finder
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+)
+
+// Option configures a ChilitoBurritoFinder
+type Option func(*finderOptions)
+
+// finderOptions collects settings from Options before the finder is built,
+// so that option order does not matter. Options only record settings, so
+// they can safely be applied more than once.
+type finderOptions struct {
+	geocoders     chainOptions[Geocoder]
+	locators      chainOptions[StoreLocator]
+	mergeLocators bool
+	checker       MenuChecker
+	parallelism   int
+	perHostLimit  int
+	logger        *slog.Logger
+	geocodeCache  *GeocodeCache
+	menuCache     *MenuCache
+	storeIDCache  *StoreIDCache
+	refreshCache  bool
+	config        Config
+	fromConfig    *Config
+	item          *Item
+	transport     http.RoundTripper
+	rateLimits    rateLimits
+	retry         RetryPolicy
+	client        *http.Client
+	timeouts      Timeouts
+	ownTimeouts   bool
+}
+
+// named is implemented by every pluggable provider
+type named interface {
+	Name() string
+}
+
+// chainOptions describes how to build an ordered chain of providers
+type chainOptions[T named] struct {
+	explicit []T // replaces the defaults when non-nil
+	extras   []T
+	order    []string
+	disabled map[string]bool
+}
+
+func (c *chainOptions[T]) replace(items []T) {
+	c.explicit = append([]T{}, items...)
+}
+
+func (c *chainOptions[T]) register(item T) {
+	c.extras = append(c.extras, item)
+}
+
+func (c *chainOptions[T]) reorder(names []string) {
+	c.order = append([]string{}, names...)
+}
+
+func (c *chainOptions[T]) disable(names []string) {
+	if c.disabled == nil {
+		c.disabled = make(map[string]bool)
+	}
+	for _, name := range names {
+		c.disabled[name] = true
+	}
+}
+
+// resolve builds the final chain from defaults and the recorded options
+func (c *chainOptions[T]) resolve(defaults []T) []T {
+	chain := defaults
+	if c.explicit != nil {
+		chain = append([]T{}, c.explicit...)
+	}
+
+	// Registered providers replace same-named ones or are appended
+	for _, extra := range c.extras {
+		replaced := false
+		for i, item := range chain {
+			if item.Name() == extra.Name() {
+				chain[i] = extra
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			chain = append(chain, extra)
+		}
+	}
+
+	if c.order != nil {
+		byName := make(map[string]T, len(chain))
+		for _, item := range chain {
+			byName[item.Name()] = item
+		}
+		ordered := make([]T, 0, len(c.order))
+		for _, name := range c.order {
+			if item, ok := byName[name]; ok {
+				ordered = append(ordered, item)
+			}
+		}
+		chain = ordered
+	}
+
+	var enabled []T
+	for _, item := range chain {
+		if !c.disabled[item.Name()] {
+			enabled = append(enabled, item)
+		}
+	}
+	return enabled
+}
+
+// WithGeocoders replaces the default geocoder chain. Geocoders are tried in
+// the order given until one succeeds.
+func WithGeocoders(geocoders ...Geocoder) Option {
+	return func(o *finderOptions) { o.geocoders.replace(geocoders) }
+}
+
+// WithGeocoder registers an additional geocoder at the end of the chain. A
+// geocoder with the same name as an existing one replaces it in place, which
+// makes it easy to point "nominatim" at a self-hosted instance.
+func WithGeocoder(geocoder Geocoder) Option {
+	return func(o *finderOptions) { o.geocoders.register(geocoder) }
+}
+
+// WithGeocoderOrder restricts the chain to the named geocoders, tried in the
+// given order. Names that are not registered are ignored.
+func WithGeocoderOrder(names ...string) Option {
+	return func(o *finderOptions) { o.geocoders.reorder(names) }
+}
+
+// WithoutGeocoders removes the named geocoders from the chain
+func WithoutGeocoders(names ...string) Option {
+	return func(o *finderOptions) { o.geocoders.disable(names) }
+}
+
+// WithStoreLocators replaces the default store locators
+func WithStoreLocators(locators ...StoreLocator) Option {
+	return func(o *finderOptions) { o.locators.replace(locators) }
+}
+
+// WithStoreLocator registers an additional store locator, replacing any
+// existing locator with the same name
+func WithStoreLocator(locator StoreLocator) Option {
+	return func(o *finderOptions) { o.locators.register(locator) }
+}
+
+// WithStoreLocatorOrder restricts the locators to the named ones, in the
+// given order. Names that are not registered are ignored.
+func WithStoreLocatorOrder(names ...string) Option {
+	return func(o *finderOptions) { o.locators.reorder(names) }
+}
+
+// WithoutStoreLocators removes the named store locators
+func WithoutStoreLocators(names ...string) Option {
+	return func(o *finderOptions) { o.locators.disable(names) }
+}
+
+// WithMergedLocators runs every store locator and merges their results
+// instead of stopping at the first one that succeeds. Duplicates are dropped
+// in favor of the locator listed first.
+func WithMergedLocators() Option {
+	return func(o *finderOptions) { o.mergeLocators = true }
+}
+
+// WithMenuChecker replaces the default menu checker. Use AnyOf and AllOf to
+// combine several checkers.
+func WithMenuChecker(checker MenuChecker) Option {
+	return func(o *finderOptions) { o.checker = checker }
+}
+
+// WithParallelism sets how many stores are checked at once. The result is
+// still the nearest confirmed store regardless of which check finishes first.
+func WithParallelism(n int) Option {
+	return func(o *finderOptions) { o.parallelism = n }
+}
+
+// WithPerHostLimit caps the number of concurrent requests to any single host
+func WithPerHostLimit(n int) Option {
+	return func(o *finderOptions) { o.perHostLimit = n }
+}
+
+// WithLogger sets the logger for progress and diagnostics. Records carry
+// attributes such as store_id, provider and url. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *finderOptions) { o.logger = logger }
+}
+
+// WithGeocodeCache looks addresses up in cache before asking the geocoders
+// and stores every successful geocoding result in it
+func WithGeocodeCache(cache *GeocodeCache) Option {
+	return func(o *finderOptions) { o.geocodeCache = cache }
+}
+
+// WithMenuCache answers menu checks for stores with a fresh entry in cache
+// without fetching anything, and stores every definite answer in it
+func WithMenuCache(cache *MenuCache) Option {
+	return func(o *finderOptions) { o.menuCache = cache }
+}
+
+// WithStoreIDCache remembers which official store number, if any, each
+// OpenStreetMap location was matched to
+func WithStoreIDCache(cache *StoreIDCache) Option {
+	return func(o *finderOptions) { o.storeIDCache = cache }
+}
+
+// WithCacheRefresh ignores cached entries while still writing fresh results
+// back to the caches
+func WithCacheRefresh() Option {
+	return func(o *finderOptions) { o.refreshCache = true }
+}
+
+// WithEndpoints points the built-in providers and the store search at other
+// upstream URLs. Empty fields keep the URL from WithConfig or the default.
+// Providers registered with other options are not affected.
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(o *finderOptions) { o.config.Endpoints = o.config.Endpoints.merge(endpoints) }
+}
+
+// WithItem searches for item instead of the configured one. The item's
+// search terms, exclusions, selectors, paths and known stores only affect
+// the default menu checker, not one set with WithMenuChecker.
+func WithItem(item Item) Option {
+	return func(o *finderOptions) { o.item = &item }
+}
+
+// WithConfig applies every setting of cfg: endpoints, credentials, menu
+// matching, timeouts, retries and limits. It is applied before any other
+// option, so options such as WithEndpoints or WithParallelism override
+// individual settings wherever they appear. If given more than once, the
+// last cfg is used.
+func WithConfig(cfg Config) Option {
+	return func(o *finderOptions) { o.fromConfig = &cfg }
+}
+
+// applyConfig sets everything cfg configures. Settings cfg leaves unset
+// keep their defaults.
+func (o *finderOptions) applyConfig(cfg Config) {
+	cfg = cfg.withDefaults()
+	o.config = cfg
+	o.parallelism = cfg.Parallelism
+	o.perHostLimit = cfg.PerHostLimit
+	o.retry = cfg.retryPolicy()
+	o.timeouts = cfg.timeouts()
+	o.ownTimeouts = o.timeouts != DefaultTimeouts
+	for host, limit := range cfg.RateLimits {
+		WithRateLimit(host, RateLimit{Interval: time.Duration(limit.Interval), Burst: limit.Burst})(o)
+	}
+}
+
+// WithRateLimit replaces the rate limit for requests to host, e.g.
+// "nominatim.openstreetmap.org". A zero RateLimit removes the limit.
+func WithRateLimit(host string, limit RateLimit) Option {
+	return func(o *finderOptions) {
+		if o.rateLimits.overrides == nil {
+			o.rateLimits.overrides = make(map[string]RateLimit)
+		}
+		o.rateLimits.overrides[host] = limit
+	}
+}
+
+// WithRequestDelay spaces requests to each host at least d apart, on top of
+// the per-host rate limits
+func WithRequestDelay(d time.Duration) Option {
+	return func(o *finderOptions) { o.rateLimits.delay = d }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy for every request the finder
+// makes
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *finderOptions) { o.retry = policy }
+}
+
+// WithHTTPClient makes the finder send every request with a copy of client.
+// The client's Transport (or, if nil, the finder's own) is wrapped with the
+// retry policy and the per-host and rate limits. Its Jar and Timeout are kept
+// when set.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *finderOptions) { o.client = client }
+}
+
+// WithTransport sends every request through transport instead of the
+// finder's shared connection pool. The retry policy and the per-host and rate
+// limits still apply on top of it. Use a Recorder or Replayer to capture
+// traffic and debug it offline; a Recorder without a Transport sends the
+// requests through the finder's own pool and timeouts.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *finderOptions) { o.transport = transport }
+}
+
+// WithTimeouts replaces DefaultTimeouts. Zero fields keep the default.
+// Transport-level timeouts only apply to the finder's own transport, not to
+// one set with WithTransport or WithHTTPClient, except a Recorder without a
+// Transport of its own.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(o *finderOptions) {
+		o.timeouts = timeouts.withDefaults()
+		o.ownTimeouts = true
+	}
+}
+
+// ownTransport returns the finder's own connection pool
+func (o *finderOptions) ownTransport() http.RoundTripper {
+	if o.ownTimeouts {
+		return newTransport(o.timeouts)
+	}
+	return sharedTransport()
+}
+
+// newClient builds the client every request of the finder goes through
+func (o *finderOptions) newClient() *http.Client {
+	client := &http.Client{}
+	if o.client != nil {
+		*client = *o.client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = o.transport
+	}
+	if base == nil {
+		base = o.ownTransport()
+	}
+	// A Recorder without a transport of its own records the finder's traffic
+	if rec, ok := base.(*Recorder); ok && rec.Transport == nil {
+		rec.Transport = o.ownTransport()
+	}
+	client.Transport = o.retry.Transport(newHostLimitTransport(newRateLimitTransport(base, o.rateLimits), o.perHostLimit))
+
+	// Menu pages set cookies that later requests are expected to send back
+	if client.Jar == nil {
+		client.Jar, _ = cookiejar.New(nil)
+	}
+	if client.Timeout == 0 {
+		client.Timeout = o.timeouts.Request
+	}
+	return client
+}
+
+// build applies the resolved options to f
+func (o *finderOptions) build(f *ChilitoBurritoFinder, client *http.Client) {
+	f.geocoders = o.geocoders.resolve(o.config.geocoders(client))
+	f.locators = o.locators.resolve(o.config.locators(client))
+	f.mergeLocators = o.mergeLocators
+	f.parallelism = o.parallelism
+	f.geocodeCache = o.geocodeCache
+	f.menuCache = o.menuCache
+	f.storeIDCache = o.storeIDCache
+	f.storeMatcher = &TacoBellLocator{Client: client, BaseURL: o.config.Endpoints.TacoBell}
+	f.websiteURL = o.config.Endpoints.tacoBell()
+	f.menuScraper = o.config.scraper(client, Item{})
+	f.refreshCache = o.refreshCache
+	f.item = o.config.SelectedItem()
+	if o.item != nil {
+		f.item = *o.item
+	}
+	f.itemKey = f.item.key(o.config.Menu.MatchThreshold)
+	f.logger = o.logger
+	if f.logger == nil {
+		f.logger = discardLogger
+	}
+	f.checker = o.checker
+	if f.checker == nil {
+		f.checker = o.config.menuChecker(client, f.item)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+// Output formats accepted by -format
+var outputFormats = map[string]bool{
+	"text":    true,
+	"json":    true,
+	"ndjson":  true,
+	"csv":     true,
+	"geojson": true,
+}
+
+// schemaVersion is bumped whenever a field of storeRecord or searchReport
+// changes meaning or is removed. Adding fields does not bump it.
+//
+//	1  initial format
+//	2  status "not_found" no longer covers stores that list the item as
+//	   unavailable; those are reported as "not_available"
+const schemaVersion = 2
+
+// storeRecord is the machine-readable description of one checked store.
+// It is emitted as-is by -format json (inside searchReport.Stores) and
+// ndjson (one per line), as one row per store by csv, and as the properties
+// of a Point feature by geojson.
+//
+//	store_id           Official store number, or an "osm-..." placeholder
+//	store_id_fallback  true when store_id is a placeholder
+//	name, address, phone
+//	latitude, longitude
+//	distance_km        Distance from the searched address
+//	source             Store locator that found the store ("tacobell", "overpass")
+//	status             "found", "not_found" (not listed), "not_available"
+//	                   (listed but unavailable), "unknown" or "error"
+//	checker            Menu checker that decided the status
+//	matched_url        Menu page the item was found on
+//	matched_term       Search term that matched
+//	evidence           Snippet of page text around the match
+//	confidence         How well the evidence matches the item, from 0 to 1
+//	checked_at         RFC 3339 time the check started
+//	duration_ms        How long the check took
+//	cached             true when the result came from the menu cache;
+//	                   checked_at is then when the original check ran
+//	attempts           HTTP requests sent for the check, including retries
+//	item               Name of the menu item that was looked for
+//	error              Why the check failed, when status is "error"
+type storeRecord struct {
+	locationRecord
+	StoreIDFallback bool      `json:"store_id_fallback"`
+	Status          string    `json:"status"`
+	Checker         string    `json:"checker"`
+	MatchedURL      string    `json:"matched_url"`
+	MatchedTerm     string    `json:"matched_term"`
+	Evidence        string    `json:"evidence"`
+	Confidence      float64   `json:"confidence"`
+	CheckedAt       time.Time `json:"checked_at"`
+	DurationMs      int64     `json:"duration_ms"`
+	Cached          bool      `json:"cached"`
+	Attempts        int       `json:"attempts"`
+	Item            string    `json:"item"`
+	Error           string    `json:"error"`
+}
+
+// locationRecord describes a store without any menu check. It is the
+// element type of the serve command's /v1/stores response.
+type locationRecord struct {
+	StoreID    string  `json:"store_id"`
+	Name       string  `json:"name"`
+	Address    string  `json:"address"`
+	Phone      string  `json:"phone"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	DistanceKm float64 `json:"distance_km"`
+	Source     string  `json:"source"`
+}
+
+// newLocationRecord converts a location to its output form
+func newLocationRecord(location finder.TacoBellLocation) locationRecord {
+	return locationRecord{
+		StoreID:    location.StoreID,
+		Name:       location.Name,
+		Address:    location.Address,
+		Phone:      location.PhoneNumber,
+		Latitude:   location.Latitude,
+		Longitude:  location.Longitude,
+		DistanceKm: location.Distance,
+		Source:     location.Source,
+	}
+}
+
+// csvHeader lists the storeRecord fields in CSV column order
+var csvHeader = []string{
+	"store_id", "store_id_fallback", "name", "address", "phone",
+	"latitude", "longitude", "distance_km", "source", "status", "checker",
+	"matched_url", "matched_term", "evidence", "checked_at", "duration_ms", "error", "cached", "attempts", "item", "confidence",
+}
+
+// searchReport is the document emitted by -format json
+type searchReport struct {
+	SchemaVersion int           `json:"schema_version"`
+	Item          string        `json:"item"`
+	Address       string        `json:"address"`
+	RadiusMeters  int           `json:"radius_m"`
+	Interrupted   bool          `json:"interrupted"`
+	Stores        []storeRecord `json:"stores"`
+}
+
+// newStoreRecord converts a check result to its output form
+func newStoreRecord(result finder.CheckResult) storeRecord {
+	record := storeRecord{
+		locationRecord:  newLocationRecord(result.Location),
+		StoreIDFallback: result.StoreIDFallback,
+		Status:          result.Status.String(),
+		Checker:         result.Checker,
+		MatchedURL:      result.MatchedURL,
+		MatchedTerm:     result.MatchedTerm,
+		Evidence:        result.Evidence,
+		Confidence:      result.Confidence,
+		CheckedAt:       result.CheckedAt.UTC(),
+		DurationMs:      result.Duration.Milliseconds(),
+		Cached:          result.Cached,
+		Attempts:        result.Attempts,
+		Item:            result.Item,
+	}
+	if result.Err != nil {
+		record.Error = result.Err.Error()
+	}
+	return record
+}
+
+// newSearchReport builds the JSON document for a search
+func newSearchReport(item, address string, radius int, interrupted bool, results []finder.CheckResult) searchReport {
+	report := searchReport{
+		SchemaVersion: schemaVersion,
+		Item:          item,
+		Address:       address,
+		RadiusMeters:  radius,
+		Interrupted:   interrupted,
+		Stores:        make([]storeRecord, len(results)),
+	}
+	for i, result := range results {
+		report.Stores[i] = newStoreRecord(result)
+	}
+	return report
+}
+
+// writeResults writes a search report in one of the machine-readable formats
+func writeResults(w io.Writer, format string, report searchReport) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, record := range report.Stores {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		return writeCSV(w, report.Stores)
+	case "geojson":
+		return writeGeoJSON(w, report.Stores)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+// writeCSV writes one row per store under a header row
+func writeCSV(w io.Writer, records []storeRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.StoreID, strconv.FormatBool(r.StoreIDFallback), r.Name, r.Address, r.Phone,
+			formatFloat(r.Latitude), formatFloat(r.Longitude), formatFloat(r.DistanceKm),
+			r.Source, r.Status, r.Checker, r.MatchedURL, r.MatchedTerm, r.Evidence,
+			r.CheckedAt.Format(time.RFC3339), strconv.FormatInt(r.DurationMs, 10), r.Error,
+			strconv.FormatBool(r.Cached), strconv.Itoa(r.Attempts), r.Item, formatFloat(r.Confidence),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// writeGeoJSON writes a FeatureCollection with one Point per store. Stores
+// without known coordinates have a null geometry.
+func writeGeoJSON(w io.Writer, records []storeRecord) error {
+	type geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"` // [longitude, latitude]
+	}
+	type feature struct {
+		Type       string      `json:"type"`
+		Geometry   *geometry   `json:"geometry"`
+		Properties storeRecord `json:"properties"`
+	}
+	collection := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: []feature{}}
+
+	for _, record := range records {
+		f := feature{Type: "Feature", Properties: record}
+		if record.Latitude != 0 || record.Longitude != 0 {
+			f.Geometry = &geometry{Type: "Point", Coordinates: [2]float64{record.Longitude, record.Latitude}}
+		}
+		collection.Features = append(collection.Features, f)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(collection)
+}
+
+// writeText prints the human-readable summary of a search
+func writeText(w io.Writer, item string, results []finder.CheckResult, listAll bool) {
+	if listAll {
+		writeStatusTable(w, results)
+	}
+
+	var found, unavailable []finder.CheckResult
+	for _, result := range results {
+		switch result.Status {
+		case finder.StatusFound:
+			found = append(found, result)
+		case finder.StatusNotAvailable:
+			unavailable = append(unavailable, result)
+		}
+	}
+
+	// Stores that list the item greyed out are not worth the drive, but
+	// are worth knowing about
+	if len(unavailable) > 0 {
+		fmt.Fprintf(w, "\n%s is listed but not available at:\n", item)
+		for _, result := range unavailable {
+			fmt.Fprintf(w, "  %s, %s (%.2f km): %s\n", result.Location.Name, result.Location.Address,
+				result.Location.Distance, result.Evidence)
+		}
+	}
+
+	if len(found) == 0 {
+		fmt.Fprintf(w, "\nNo Taco Bell locations with %s found within the search radius.\n", item)
+		fmt.Fprintln(w, "Try increasing the search radius or using a different starting address.")
+		return
+	}
+	for _, result := range found {
+		location := result.Location
+		fmt.Fprintf(w, "\nSUCCESS! Found %s at: %s\n", item, location.Name)
+		fmt.Fprintf(w, "Address: %s\n", location.Address)
+		fmt.Fprintf(w, "Distance: %.2f km\n", location.Distance)
+		fmt.Fprintf(w, "Phone: %s\n", location.PhoneNumber)
+		fmt.Fprintf(w, "Store: %s (source: %s)\n", location.StoreID, location.Source)
+		writeEvidence(w, result)
+	}
+}
+
+// writeEvidence shows why a store was reported as having the item
+func writeEvidence(w io.Writer, result finder.CheckResult) {
+	fmt.Fprintf(w, "Evidence: %s", result.Checker)
+	if result.MatchedTerm != "" {
+		fmt.Fprintf(w, " matched %q", result.MatchedTerm)
+	}
+	if result.MatchedURL != "" {
+		fmt.Fprintf(w, " at %s", result.MatchedURL)
+	}
+	if result.Confidence > 0 {
+		fmt.Fprintf(w, " (confidence %.2f)", result.Confidence)
+	}
+	if result.Cached {
+		fmt.Fprintf(w, " (cached %s)", formatAge(time.Since(result.CheckedAt)))
+	}
+	fmt.Fprintln(w)
+	if result.Evidence != "" {
+		fmt.Fprintf(w, "  %s\n", result.Evidence)
+	}
+}
+
+// writeStatusTable lists the status of every store that was checked
+func writeStatusTable(w io.Writer, results []finder.CheckResult) {
+	fmt.Fprintf(w, "\nChecked %d stores:\n", len(results))
+	for _, result := range results {
+		status := result.Status.String()
+		switch {
+		case result.Err != nil:
+			status += ": " + result.Err.Error()
+		case result.StoreIDFallback:
+			status += " (no official store number)"
+		case result.Cached:
+			status += " (cached " + formatAge(time.Since(result.CheckedAt)) + ")"
+		}
+		fmt.Fprintf(w, "  %7.2f km  %-20s %-10s %6v  %s\n",
+			result.Location.Distance, result.Location.Name, status,
+			result.Duration.Round(100*time.Millisecond), result.Location.Address)
+	}
+}
+
+// writeInterrupted reports the progress of a search that was canceled
+func writeInterrupted(w io.Writer, item string, err *finder.SearchInterruptedError, elapsed time.Duration) {
+	fmt.Fprintf(w, "\nSearch interrupted after %v\n", elapsed.Round(time.Second))
+	fmt.Fprintf(w, "Found %d Taco Bell locations, checked %d without finding the %s:\n",
+		len(err.Located), len(err.Checked), item)
+	for _, location := range err.Checked {
+		fmt.Fprintf(w, "  %s, %s (%.2f km)\n", location.Name, location.Address, location.Distance)
+	}
+	if len(err.Found) > 0 {
+		fmt.Fprintf(w, "%s confirmed at:\n", item)
+		for _, location := range err.Found {
+			fmt.Fprintf(w, "  %s, %s (%.2f km)\n", location.Name, location.Address, location.Distance)
+		}
+	}
+}
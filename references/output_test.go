@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+func TestCachedResultAge(t *testing.T) {
+	result := finder.CheckResult{
+		Location:  finder.TacoBellLocation{Name: "Taco Bell", StoreID: "031447"},
+		Status:    finder.StatusFound,
+		Checker:   "scrape",
+		CheckedAt: time.Now().Add(-3*time.Hour - time.Minute),
+		Cached:    true,
+	}
+	var evidence, table bytes.Buffer
+	writeEvidence(&evidence, result)
+	writeStatusTable(&table, []finder.CheckResult{result})
+	for name, out := range map[string]string{"evidence": evidence.String(), "status table": table.String()} {
+		if !strings.Contains(out, "(cached 3h ago)") {
+			t.Errorf("%s = %q, want the cache age", name, out)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{47 * time.Hour, "47h ago"},
+		{72 * time.Hour, "3d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenReport covers a found store, a store that lists the item as
+// unavailable and a failed check of a store without coordinates
+func goldenReport() searchReport {
+	checkedAt := time.Date(2026, 3, 14, 18, 30, 0, 0, time.UTC)
+	return newSearchReport("Chili Cheese Burrito", "1 Glen Bell Way", 16093, false, []finder.CheckResult{
+		{
+			Location: finder.TacoBellLocation{
+				StoreID: "031447", Name: "Taco Bell", Address: "1 Glen Bell Way, Irvine, CA 92618",
+				PhoneNumber: "(949) 555-0100", Latitude: 33.6562, Longitude: -117.7431, Distance: 0.42, Source: "tacobell",
+			},
+			Status: finder.StatusFound, Checker: "scrape",
+			MatchedURL: "https://www.tacobell.com/food/burritos?store=031447", MatchedTerm: "chilito",
+			Evidence: `Chili Cheese Burrito, aka "Chilito"`, Confidence: 1,
+			CheckedAt: checkedAt, Duration: 1250 * time.Millisecond, Attempts: 2, Item: "Chili Cheese Burrito",
+		},
+		{
+			Location: finder.TacoBellLocation{
+				StoreID: "018678", Name: "Taco Bell Cantina", Address: "2 Main St, Irvine, CA 92614",
+				Latitude: 33.68, Longitude: -117.8, Distance: 3.1, Source: "tacobell",
+			},
+			Status: finder.StatusNotAvailable, Checker: "scrape",
+			MatchedURL: "https://www.tacobell.com/food/burritos?store=018678", MatchedTerm: "chili cheese burrito",
+			Evidence: "Chili Cheese Burrito Unavailable", Confidence: 0.9,
+			CheckedAt: checkedAt, Duration: 800 * time.Millisecond, Cached: true, Attempts: 1, Item: "Chili Cheese Burrito",
+		},
+		{
+			Location:        finder.TacoBellLocation{StoreID: "osm-node-6200000001", Name: "Taco Bell", Distance: 7.5, Source: "overpass"},
+			StoreIDFallback: true,
+			Status:          finder.StatusError,
+			CheckedAt:       checkedAt,
+			Attempts:        3,
+			Item:            "Chili Cheese Burrito",
+			Err:             errors.New("fetching menu: 503 Service Unavailable"),
+		},
+	})
+}
+
+func TestWriteResultsGolden(t *testing.T) {
+	for _, format := range []string{"json", "ndjson", "csv", "geojson"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeResults(&buf, format, goldenReport()); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", "golden", "report."+format)
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s output differs from %s (run with -update to rewrite it):\n%s", format, path, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package finder
+
+import (
+	"context"
+	"sync"
+)
+
+// Default concurrency settings for menu checks
+const (
+	DefaultParallelism  = 4
+	DefaultPerHostLimit = 2
+)
+
+// checkOutcome is the result of checking the store at index
+type checkOutcome struct {
+	index  int
+	result CheckResult
+}
+
+// checkLocations checks the menus of locations concurrently, using at most
+// f.parallelism workers, and calls visit with each result in the order of
+// locations: a result is delivered only once it and every location before
+// it have been checked. Since locations are sorted by distance, visit sees
+// stores nearest first no matter which check finishes first.
+//
+// Checking stops as soon as visit returns false. If ctx is done before every
+// result was delivered, checkLocations returns ctx's error along with the
+// completed results that were still waiting on a nearer store.
+func (f *ChilitoBurritoFinder) checkLocations(ctx context.Context, locations []TacoBellLocation, visit func(result CheckResult) bool) ([]CheckResult, error) {
+	if len(locations) == 0 {
+		return nil, nil
+	}
+
+	// Stop handing out work before waiting for the workers, so that an early
+	// stop does not check every remaining store first
+	workCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	workers := f.parallelism
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(locations) {
+		workers = len(locations)
+	}
+
+	// Hand out work nearest first; the buffered outcomes channel lets
+	// workers finish even after we stop listening
+	jobs := make(chan int)
+	outcomes := make(chan checkOutcome, len(locations))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				location := locations[i]
+				f.logger.Debug("checking menu", "store_id", location.StoreID, "name", location.Name,
+					"distance_km", location.Distance)
+				outcomes <- checkOutcome{index: i, result: f.CheckMenuContext(workCtx, location)}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range locations {
+			select {
+			case jobs <- i:
+			case <-workCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// interrupted collects the completed outcomes that were never delivered
+	pending := make(map[int]checkOutcome)
+	interrupted := func() ([]CheckResult, error) {
+		cancel()
+		wg.Wait()
+		close(outcomes)
+		for outcome := range outcomes {
+			pending[outcome.index] = outcome
+		}
+		var undelivered []CheckResult
+		for i := range locations {
+			if outcome, ok := pending[i]; ok && outcome.result.Err == nil {
+				undelivered = append(undelivered, outcome.result)
+			}
+		}
+		return undelivered, ctx.Err()
+	}
+
+	// Deliver outcomes in order as the prefix of completed checks grows
+	next := 0
+	for next < len(locations) {
+		select {
+		case outcome := <-outcomes:
+			pending[outcome.index] = outcome
+		case <-ctx.Done():
+			return interrupted()
+		}
+
+		for {
+			outcome, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			// A check cut short by the caller's cancellation has no answer
+			if outcome.result.Err != nil && ctx.Err() != nil {
+				return interrupted()
+			}
+			if !visit(outcome.result) {
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
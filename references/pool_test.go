@@ -0,0 +1,178 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckLocationsStopsEarly(t *testing.T) {
+	u := newUpstream(t)
+	page := fixture(t, "menu/burritos_chilito.html")
+	u.handle("GET /food/burritos", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		page(w, r)
+	})
+
+	var locations []TacoBellLocation
+	for i := 0; i < 30; i++ {
+		locations = append(locations, TacoBellLocation{StoreID: fmt.Sprintf("%06d", i+1), Distance: float64(i)})
+	}
+	checker := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos"}}
+	f := newTestFinder(u, WithMenuChecker(checker), WithParallelism(2))
+
+	// Every store has it, so the nearest one ends the search
+	visited := 0
+	_, err := f.checkLocations(context.Background(), locations, func(result CheckResult) bool {
+		visited++
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visited != 1 {
+		t.Errorf("visited %d results, want 1", visited)
+	}
+	if n := u.count("/food/"); n > 4 {
+		t.Errorf("%d menu pages fetched after stopping at the first store, want at most 4", n)
+	}
+}
+
+func TestNearestFoundStoreWinsWhicheverCheckFinishesFirst(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+	withChilito := fixture(t, "menu/burritos_chilito.html")
+	without := fixture(t, "menu/burritos.html")
+
+	// The nearest store's burritos page is held back until the farther
+	// Technology Dr store, a known location, has been checked
+	farther := make(chan struct{})
+	var once sync.Once
+	u.handle("GET /food/{page}", func(w http.ResponseWriter, r *http.Request) {
+		switch store := r.URL.Query().Get("store"); {
+		case store == "018678":
+			once.Do(func() { close(farther) })
+		case store == "031447" && r.PathValue("page") == "burritos":
+			<-farther
+			time.Sleep(50 * time.Millisecond)
+			withChilito(w, r)
+			return
+		}
+		without(w, r)
+	})
+
+	f := newTestFinder(u, WithParallelism(3))
+	results, err := f.FindChilitoBurritosContext(context.Background(), "1 Glen Bell Way, Irvine, CA", 100000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Location.StoreID != "031447" {
+		t.Errorf("got %+v, want only the nearest store 031447", results)
+	}
+}
+
+func TestPerHostLimit(t *testing.T) {
+	u := newUpstream(t)
+	page := fixture(t, "menu/burritos.html")
+	var inFlight, most atomic.Int32
+	u.handle("GET /food/{page}", func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := most.Load()
+			if n <= m || most.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		page(w, r)
+	})
+
+	var locations []TacoBellLocation
+	for i := 0; i < 4; i++ {
+		locations = append(locations, TacoBellLocation{StoreID: fmt.Sprintf("%06d", i+1), Distance: float64(i)})
+	}
+	// The default checker sends its requests through the finder's client
+	f := newTestFinder(u, WithParallelism(4), WithPerHostLimit(1))
+	if _, err := f.checkLocations(context.Background(), locations, func(CheckResult) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if n := u.count("/food/"); n != len(locations)*len(DefaultMenuPaths) {
+		t.Errorf("%d menu pages fetched, want %d", n, len(locations)*len(DefaultMenuPaths))
+	}
+	if n := most.Load(); n != 1 {
+		t.Errorf("%d concurrent requests to the upstream, want 1", n)
+	}
+}
+
+// notifyingChecker reports the store ID of every finished check on done
+type notifyingChecker struct {
+	MenuChecker
+	done chan<- string
+}
+
+func (c *notifyingChecker) CheckMenu(ctx context.Context, location TacoBellLocation) CheckResult {
+	result := c.MenuChecker.CheckMenu(ctx, location)
+	c.done <- location.StoreID
+	return result
+}
+
+func TestSearchInterrupted(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+	withChilito := fixture(t, "menu/burritos_chilito.html")
+	without := fixture(t, "menu/burritos.html")
+
+	// The nearest store never answers, so the two farther checks finish
+	// but cannot be delivered
+	u.handle("GET /food/burritos", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("store") {
+		case "031447":
+			<-r.Context().Done()
+		case "004012":
+			withChilito(w, r)
+		default:
+			without(w, r)
+		}
+	})
+
+	done := make(chan string, 3)
+	checker := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos"}}
+	f := newTestFinder(u, WithMenuChecker(&notifyingChecker{MenuChecker: checker, done: done}), WithParallelism(3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		<-done
+		cancel()
+	}()
+	results, err := f.FindChilitoBurritosContext(ctx, "1 Glen Bell Way, Irvine, CA", 100000, 0)
+
+	var interrupted *SearchInterruptedError
+	if !errors.As(err, &interrupted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want a SearchInterruptedError wrapping context.Canceled", err)
+	}
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Location.StoreID+" "+result.Status.String())
+	}
+	if want := []string{"018678 not_found", "004012 found"}; strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if len(interrupted.Located) != 3 || len(interrupted.Checked) != 1 || len(interrupted.Found) != 1 ||
+		interrupted.Found[0].StoreID != "004012" {
+		t.Errorf("interrupted = %+v", interrupted)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "after checking 2 of 3 stores") {
+		t.Errorf("Error() = %q", msg)
+	}
+}
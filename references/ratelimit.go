@@ -0,0 +1,137 @@
+package finder
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit caps the rate of requests to one host with a token bucket
+type RateLimit struct {
+	// Interval is the minimum average time between requests; 0 means unlimited
+	Interval time.Duration
+	// Burst is how many requests may go out back to back after a quiet
+	// period (at least 1)
+	Burst int
+}
+
+// DefaultRateLimits follow each provider's published usage policy, or are
+// deliberately polite where there is none. Other hosts are not limited.
+var DefaultRateLimits = map[string]RateLimit{
+	// https://operations.osmfoundation.org/policies/nominatim/: at most 1/s
+	"nominatim.openstreetmap.org": {Interval: time.Second, Burst: 1},
+	// https://wiki.openstreetmap.org/wiki/Overpass_API: about 10,000 queries a day
+	"overpass-api.de": {Interval: 2 * time.Second, Burst: 1},
+	// Mapbox allows 600 geocoding requests per minute
+	"api.mapbox.com":   {Interval: 100 * time.Millisecond, Burst: 10},
+	"api.tacobell.com": {Interval: 500 * time.Millisecond, Burst: 2},
+	"www.tacobell.com": {Interval: 500 * time.Millisecond, Burst: 4},
+}
+
+// rateLimits resolves the limit for each host from the defaults, per-host
+// overrides and a global minimum delay
+type rateLimits struct {
+	overrides map[string]RateLimit
+	delay     time.Duration
+}
+
+// forHost returns the limit that applies to host
+func (r rateLimits) forHost(host string) RateLimit {
+	limit, ok := r.overrides[host]
+	if !ok {
+		limit = DefaultRateLimits[host]
+	}
+	if r.delay > 0 && limit.Interval < r.delay {
+		limit = RateLimit{Interval: r.delay, Burst: 1}
+	}
+	return limit
+}
+
+// tokenBucket is a token bucket that hands out reservations: a caller always
+// takes a token, possibly going into debt, and waits until the debt would
+// have been repaid. Waiters are therefore served in arrival order.
+type tokenBucket struct {
+	interval time.Duration
+	burst    float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{interval: limit.Interval, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until the caller may send a request or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens * float64(b.interval))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		// The request is not sent, so give its token back
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// rateLimitTransport delays requests so that no host receives more than its
+// RateLimit allows
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	limits rateLimits
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// newRateLimitTransport wraps base with per-host rate limits
+func newRateLimitTransport(base http.RoundTripper, limits rateLimits) http.RoundTripper {
+	return &rateLimitTransport{base: base, limits: limits, buckets: make(map[string]*tokenBucket)}
+}
+
+// bucket returns the token bucket for host, or nil if host is not limited
+func (t *rateLimitTransport) bucket(host string) *tokenBucket {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.buckets[host]
+	if !ok {
+		if limit := t.limits.forHost(host); limit.Interval > 0 {
+			b = newTokenBucket(limit)
+		}
+		t.buckets[host] = b
+	}
+	return b
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if b := t.bucket(req.URL.Hostname()); b != nil {
+		if err := b.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
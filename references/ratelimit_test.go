@@ -0,0 +1,89 @@
+package finder
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitsForHost(t *testing.T) {
+	limits := rateLimits{overrides: map[string]RateLimit{"api.mapbox.com": {}}}
+	if got := limits.forHost("nominatim.openstreetmap.org"); got.Interval != time.Second || got.Burst != 1 {
+		t.Errorf("nominatim = %+v, want the 1/s policy", got)
+	}
+	if got := limits.forHost("api.mapbox.com"); got.Interval != 0 {
+		t.Errorf("overridden mapbox = %+v, want unlimited", got)
+	}
+	if got := limits.forHost("127.0.0.1"); got.Interval != 0 {
+		t.Errorf("unknown host = %+v, want unlimited", got)
+	}
+
+	limits.delay = 3 * time.Second
+	if got := limits.forHost("nominatim.openstreetmap.org"); got.Interval != 3*time.Second || got.Burst != 1 {
+		t.Errorf("nominatim with delay = %+v, want 3s", got)
+	}
+	if got := limits.forHost("127.0.0.1"); got.Interval != 3*time.Second {
+		t.Errorf("unknown host with delay = %+v, want 3s", got)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(RateLimit{Interval: 40 * time.Millisecond, Burst: 2})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := b.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two requests from the burst, then two more 40ms apart
+	if elapsed := time.Since(start); elapsed < 75*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 80ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.wait(context.Background())
+	if err := b.wait(ctx); err == nil {
+		t.Error("wait on an exhausted bucket ignored the canceled context")
+	}
+}
+
+func TestTokenBucketRefundsCanceledWaits(t *testing.T) {
+	b := newTokenBucket(RateLimit{Interval: 50 * time.Millisecond, Burst: 1})
+	b.wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 4; i++ {
+		if err := b.wait(ctx); err == nil {
+			t.Fatal("wait on an exhausted bucket ignored the canceled context")
+		}
+	}
+	// Without refunds the next caller would wait for all five tokens
+	start := time.Now()
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("wait after canceled waits took %v, want about 50ms", elapsed)
+	}
+}
+
+func TestFinderAppliesRequestDelay(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /ping", func(w http.ResponseWriter, r *http.Request) {})
+
+	f := newTestFinder(u, WithRequestDelay(50*time.Millisecond))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := f.client.Get(u.URL + "/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 95*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 100ms", elapsed)
+	}
+}
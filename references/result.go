@@ -0,0 +1,125 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CheckStatus is the outcome of checking a store's menu
+type CheckStatus int
+
+const (
+	// StatusUnknown means the check could not tell either way, e.g. the
+	// store has no official store number or no checker had an answer
+	StatusUnknown CheckStatus = iota
+	// StatusFound means the item is on the store's menu
+	StatusFound
+	// StatusNotFound means the store's menu was read and the item is not
+	// listed on it
+	StatusNotFound
+	// StatusError means the menu could not be checked; see CheckResult.Err
+	StatusError
+	// StatusNotAvailable means the item is listed on the store's menu but
+	// marked as unavailable, sold out or not orderable there
+	StatusNotAvailable
+)
+
+// StatusNotListed is another name for StatusNotFound, to contrast it with
+// StatusNotAvailable
+const StatusNotListed = StatusNotFound
+
+var statusNames = map[CheckStatus]string{
+	StatusUnknown:      "unknown",
+	StatusFound:        "found",
+	StatusNotFound:     "not_found",
+	StatusError:        "error",
+	StatusNotAvailable: "not_available",
+}
+
+// String returns the status name used in output, e.g. "not_found"
+func (s CheckStatus) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("CheckStatus(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s CheckStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *CheckStatus) UnmarshalText(text []byte) error {
+	for status, name := range statusNames {
+		if name == string(text) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown check status %q", text)
+}
+
+// CheckResult describes the outcome of checking one store's menu and the
+// evidence behind it
+type CheckResult struct {
+	Location TacoBellLocation
+	Status   CheckStatus
+	// Item is the name of the item that was looked for
+	Item string
+	// Checker is the name of the MenuChecker that produced the answer
+	Checker string
+	// MatchedURL is the menu page the item was found on
+	MatchedURL string
+	// MatchedTerm is the search term that matched
+	MatchedTerm string
+	// Evidence is a snippet of page text around the match
+	Evidence string
+	// MenuItem is the matched product when the answer came from the page's
+	// embedded menu data
+	MenuItem *MenuItem
+	// Confidence is how well the evidence matches the item, from 0 to 1.
+	// For StatusNotFound it is the best score seen below the threshold.
+	Confidence float64
+	// StoreIDFallback is set when no official store number could be found
+	// and Location.StoreID is a placeholder
+	StoreIDFallback bool
+	// CheckedAt is when the check started and Duration how long it took
+	CheckedAt time.Time
+	Duration  time.Duration
+	// Cached is set when the result came from the menu cache. CheckedAt is
+	// then when the original check ran.
+	Cached bool
+	// Attempts is how many HTTP requests the check sent, including retries
+	Attempts int
+	// Err is set when Status is StatusError
+	Err error
+}
+
+// Found reports whether the item is on the store's menu
+func (r CheckResult) Found() bool {
+	return r.Status == StatusFound
+}
+
+// errorResult builds a StatusError result
+func errorResult(location TacoBellLocation, checker string, err error) CheckResult {
+	return CheckResult{Location: location, Status: StatusError, Checker: checker, Err: err}
+}
+
+// snippetRadius is how many characters of context surround a match in
+// CheckResult.Evidence
+const snippetRadius = 60
+
+// snippet returns the text around text[start:end] with whitespace collapsed
+func snippet(text string, start, end int) string {
+	from := start - snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := end + snippetRadius
+	if to > len(text) {
+		to = len(text)
+	}
+	return strings.Join(strings.Fields(strings.ToValidUTF8(text[from:to], "")), " ")
+}
@@ -0,0 +1,40 @@
+package finder
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckStatusText(t *testing.T) {
+	for status, name := range statusNames {
+		data, err := json.Marshal(status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `"`+name+`"` {
+			t.Errorf("%v marshals to %s, want %q", status, data, name)
+		}
+		var got CheckStatus
+		if err := json.Unmarshal(data, &got); err != nil || got != status {
+			t.Errorf("%s unmarshals to %v, %v, want %v", data, got, err, status)
+		}
+	}
+
+	var status CheckStatus
+	if err := status.UnmarshalText([]byte("maybe")); err == nil {
+		t.Error("UnmarshalText accepted an unknown status")
+	}
+	if got := CheckStatus(42).String(); got != "CheckStatus(42)" {
+		t.Errorf("String() = %q for an unknown status", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("a ", 50) + "Chili Cheese Burrito" + strings.Repeat(" b", 50)
+	start := strings.Index(text, "Chili")
+	got := snippet(text, start, start+len("Chili Cheese Burrito"))
+	if !strings.Contains(got, "a Chili Cheese Burrito b") || len(got) > len("Chili Cheese Burrito")+2*snippetRadius {
+		t.Errorf("snippet = %q", got)
+	}
+}
@@ -0,0 +1,185 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides how failed requests are retried. Rate limiting
+// (429), server errors (5xx), request timeouts (408) and network timeouts
+// or resets are retried with exponential backoff and jitter, or after the
+// delay the server asked for in Retry-After. Anything else, including 403
+// and 404, is returned at once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first;
+	// 1 disables retries
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry; each later retry waits
+	// twice as long as the one before, plus up to BaseDelay of jitter
+	BaseDelay time.Duration
+	// MaxDelay caps a single wait. A Retry-After longer than this gives up
+	// instead of waiting.
+	MaxDelay time.Duration
+	// MaxElapsed caps the total time spent on a request including waits;
+	// 0 means no cap
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy is used by the finder unless WithRetryPolicy says
+// otherwise
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	MaxElapsed:  time.Minute,
+}
+
+// Transport returns a RoundTripper that sends requests through base and
+// retries them according to the policy. Only requests whose body can be
+// replayed are retried.
+func (p RetryPolicy) Transport(base http.RoundTripper) http.RoundTripper {
+	return &retryTransport{base: base, policy: p}
+}
+
+// retryTransport implements RetryPolicy.Transport
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		countAttempt(ctx)
+		resp, err := t.base.RoundTrip(req)
+
+		retryable, wait := t.policy.classify(resp, err, attempt)
+		if !retryable || attempt >= t.policy.MaxAttempts || ctx.Err() != nil ||
+			(req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		if t.policy.MaxDelay > 0 && wait > t.policy.MaxDelay {
+			return resp, err
+		}
+		if t.policy.MaxElapsed > 0 && time.Since(start)+wait > t.policy.MaxElapsed {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain so the connection can be reused, and free its per-host slot
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		loggerFrom(ctx).Debug("retrying request", "url", redactURL(req.URL), "attempt", attempt+1,
+			"wait", wait, "status", statusOf(resp), "error", err)
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// classify reports whether the outcome of an attempt is worth retrying and
+// how long to wait first
+func (p RetryPolicy) classify(resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if err != nil {
+		return retryableError(err), p.backoff(attempt)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+	default:
+		return false, 0
+	}
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return true, wait
+	}
+	return true, p.backoff(attempt)
+}
+
+// backoff returns the wait before retry number attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.BaseDelay << (attempt - 1)
+	if p.BaseDelay > 0 {
+		wait += time.Duration(rand.Int63n(int64(p.BaseDelay)))
+	}
+	if p.MaxDelay > 0 && wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	return wait
+}
+
+// retryableError reports whether a transport error is likely to go away:
+// timeouts and dropped or refused connections
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses a Retry-After header, which holds either a number of
+// seconds or an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+type attemptsKey struct{}
+
+// withAttemptCounter returns a context that counts the HTTP attempts made on
+// its behalf, including retries
+func withAttemptCounter(ctx context.Context) (context.Context, *atomic.Int32) {
+	counter := new(atomic.Int32)
+	return context.WithValue(ctx, attemptsKey{}, counter), counter
+}
+
+// countAttempt records an attempt with the counter carried by ctx, if any
+func countAttempt(ctx context.Context) {
+	if counter, ok := ctx.Value(attemptsKey{}).(*atomic.Int32); ok {
+		counter.Add(1)
+	}
+}
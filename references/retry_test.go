@@ -0,0 +1,126 @@
+package finder
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fastRetries keeps retry tests quick
+var fastRetries = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+
+// failing replies with status to the first n requests and 200 OK afterwards
+func failing(n, status int, header http.Header) http.HandlerFunc {
+	calls := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte("ok"))
+	}
+}
+
+func TestRetryPolicyStatusClasses(t *testing.T) {
+	tests := []struct {
+		status   int
+		requests int
+		final    int
+	}{
+		{http.StatusTooManyRequests, 2, http.StatusOK},
+		{http.StatusServiceUnavailable, 2, http.StatusOK},
+		{http.StatusBadGateway, 2, http.StatusOK},
+		{http.StatusNotFound, 1, http.StatusNotFound},
+		{http.StatusForbidden, 1, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			u := newUpstream(t)
+			u.handle("GET /page", failing(1, tt.status, nil))
+
+			client := &http.Client{Transport: fastRetries.Transport(http.DefaultTransport)}
+			resp, err := client.Get(u.URL + "/page")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.final || u.count("/page") != tt.requests {
+				t.Errorf("got %d after %d requests, want %d after %d", resp.StatusCode, u.count("/page"), tt.final, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /page", failing(10, http.StatusInternalServerError, nil))
+
+	client := &http.Client{Transport: fastRetries.Transport(http.DefaultTransport)}
+	resp, err := client.Get(u.URL + "/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || u.count("/page") != 3 {
+		t.Errorf("got %d after %d requests, want 500 after 3", resp.StatusCode, u.count("/page"))
+	}
+}
+
+func TestRetryPolicyHonorsRetryAfter(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /page", failing(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}))
+
+	client := &http.Client{Transport: fastRetries.Transport(http.DefaultTransport)}
+	start := time.Now()
+	resp, err := client.Get(u.URL + "/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+}
+
+func TestRetryPolicyCapsWaits(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /page", failing(1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"3600"}}))
+
+	client := &http.Client{Transport: fastRetries.Transport(http.DefaultTransport)}
+	resp, err := client.Get(u.URL + "/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || u.count("/page") != 1 {
+		t.Errorf("got %d after %d requests, want to give up on an hour's Retry-After", resp.StatusCode, u.count("/page"))
+	}
+}
+
+func TestCheckMenuReportsAttempts(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/menu", failing(1, http.StatusTooManyRequests, nil))
+
+	checker := &ScrapingMenuChecker{
+		Client:  &http.Client{Transport: fastRetries.Transport(http.DefaultTransport)},
+		BaseURL: u.URL,
+		Paths:   []string{"/food/menu"},
+	}
+	f := newTestFinder(u, WithMenuChecker(checker))
+	result := f.CheckMenuContext(context.Background(), TacoBellLocation{PlaceID: "031447", StoreID: "031447"})
+	if result.Status != StatusNotFound {
+		t.Fatalf("Status = %v, want not_found (err %v)", result.Status, result.Err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", result.Attempts)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+// Request validation limits for the serve command
+const (
+	maxAddressLength = 200
+	maxRadiusMeters  = 200000
+	defaultRadius    = 100000
+)
+
+// server exposes a ChilitoBurritoFinder as a JSON API
+type server struct {
+	finder         *finder.ChilitoBurritoFinder
+	logger         *slog.Logger
+	requestTimeout time.Duration
+}
+
+// runServe implements "chilito serve"
+func runServe(args []string) {
+	fs := flag.NewFlagSet("chilito serve", flag.ExitOnError)
+	var common commonFlags
+	var listen string
+	var requestTimeout time.Duration
+	var shutdownTimeout time.Duration
+
+	fs.StringVar(&listen, "listen", ":8080", "Address to listen on")
+	fs.DurationVar(&requestTimeout, "request-timeout", 2*time.Minute, "Maximum time spent on a single API request")
+	fs.DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "Time allowed for in-flight requests on shutdown")
+	common.register(fs)
+	fs.Parse(args)
+
+	chilitoFinder, logger := common.setup()
+	s := &server{finder: chilitoFinder, logger: logger, requestTimeout: requestTimeout}
+
+	httpServer := &http.Server{
+		Addr:              listen,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      requestTimeout + 10*time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	// Stop accepting requests on SIGINT/SIGTERM and let in-flight ones finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "addr", listen)
+		errc <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		log.Fatalf("Server failed: %v", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Fatalf("Shutdown failed: %v", err)
+	}
+}
+
+// routes registers the API endpoints
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/search", s.handleSearch)
+	mux.HandleFunc("GET /v1/stores", s.handleStores)
+	mux.HandleFunc("GET /v1/stores/{id}/menu-check", s.handleMenuCheck)
+	return mux
+}
+
+// handleSearch serves GET /v1/search?address=...&radius=...&limit=...
+// with the same document as -format json
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	address, radius, err := searchParams(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit := 1
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			s.writeError(w, http.StatusBadRequest, errors.New("limit must be a non-negative integer"))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	results, err := s.finder.FindChilitoBurritosContext(ctx, address, radius, limit)
+	var interrupted *finder.SearchInterruptedError
+	if err != nil && !errors.As(err, &interrupted) {
+		s.writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if interrupted != nil && r.Context().Err() != nil {
+		return // client went away
+	}
+
+	// A timed-out search still returns the stores checked so far
+	s.writeJSON(w, http.StatusOK, newSearchReport(s.finder.Item().Name, address, radius, interrupted != nil, results))
+}
+
+// handleStores serves GET /v1/stores?address=...&radius=... listing the
+// stores in the radius without checking their menus
+func (s *server) handleStores(w http.ResponseWriter, r *http.Request) {
+	address, radius, err := searchParams(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	locations, err := s.finder.LocateStoresContext(ctx, address, radius)
+	if err != nil {
+		s.writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	records := make([]locationRecord, len(locations))
+	for i, location := range locations {
+		records[i] = newLocationRecord(location)
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"stores": records})
+}
+
+// handleMenuCheck serves GET /v1/stores/{id}/menu-check for an official
+// store number
+func (s *server) handleMenuCheck(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !isDigits(id) || len(id) > 10 {
+		s.writeError(w, http.StatusBadRequest, errors.New("store id must be a numeric store number"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	location := finder.TacoBellLocation{Name: "Taco Bell " + id, StoreID: id}
+	s.writeJSON(w, http.StatusOK, newStoreRecord(s.finder.CheckMenuContext(ctx, location)))
+}
+
+// searchParams validates the address and radius query parameters
+func searchParams(r *http.Request) (string, int, error) {
+	query := r.URL.Query()
+	address := query.Get("address")
+	if address == "" {
+		return "", 0, errors.New("address is required")
+	}
+	if len(address) > maxAddressLength {
+		return "", 0, fmt.Errorf("address must be at most %d characters", maxAddressLength)
+	}
+
+	radius := defaultRadius
+	if v := query.Get("radius"); v != "" {
+		var err error
+		radius, err = strconv.Atoi(v)
+		if err != nil || radius < 1 || radius > maxRadiusMeters {
+			return "", 0, fmt.Errorf("radius must be an integer between 1 and %d meters", maxRadiusMeters)
+		}
+	}
+	return address, radius, nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// writeJSON writes v as the response body
+func (s *server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("failed to write response", "error", err)
+	}
+}
+
+// writeError writes an {"error": "..."} response
+func (s *server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/chilito/finder"
+)
+
+// newTestServer serves the API with a finder that only talks to upstream
+func newTestServer(t *testing.T, upstream http.Handler) *httptest.Server {
+	t.Helper()
+	u := httptest.NewServer(upstream)
+	t.Cleanup(u.Close)
+
+	f := finder.NewChilitoBurritoFinder(
+		finder.WithEndpoints(finder.Endpoints{
+			TacoBell:    u.URL,
+			TacoBellAPI: u.URL,
+			Nominatim:   u.URL + "/search",
+			Overpass:    u.URL + "/api/interpreter",
+			Mapbox:      u.URL,
+		}),
+		finder.WithGeocoderOrder("tacobell"),
+		finder.WithStoreLocatorOrder("tacobell"),
+		finder.WithRetryPolicy(finder.RetryPolicy{MaxAttempts: 1}),
+	)
+	s := &server{finder: f, logger: slog.New(slog.NewTextHandler(io.Discard, nil)), requestTimeout: 30 * time.Second}
+	api := httptest.NewServer(s.routes())
+	t.Cleanup(api.Close)
+	return api
+}
+
+// serveFixture replies with the contents of testdata/name
+func serveFixture(t *testing.T, name string) http.HandlerFunc {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) { w.Write(body) }
+}
+
+// fixtureUpstream replays a geocoding result, three stores and their menus.
+// Only store 004012's burritos page lists the item.
+func fixtureUpstream(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /location/v1/{address}", serveFixture(t, "tacobell_geocode.json"))
+	mux.HandleFunc("GET /tacobellwebservices/v4/tacobell/stores", serveFixture(t, "tacobell_stores.json"))
+	withChilito := serveFixture(t, "menu/burritos_chilito.html")
+	without := serveFixture(t, "menu/burritos.html")
+	mux.HandleFunc("GET /food/{page}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("store") == "004012" && r.PathValue("page") == "burritos" {
+			withChilito(w, r)
+			return
+		}
+		without(w, r)
+	})
+	return mux
+}
+
+// get requests path from api and decodes the JSON response into v
+func get(t *testing.T, api *httptest.Server, path string, v any) int {
+	t.Helper()
+	resp, err := http.Get(api.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s: Content-Type = %q", path, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServeRejectsBadParameters(t *testing.T) {
+	api := newTestServer(t, http.NotFoundHandler())
+	paths := []string{
+		"/v1/search",
+		"/v1/search?address=" + url.QueryEscape(strings.Repeat("1 Main St ", 30)),
+		"/v1/search?address=Irvine&radius=far",
+		"/v1/search?address=Irvine&radius=0",
+		"/v1/search?address=Irvine&radius=200001",
+		"/v1/search?address=Irvine&limit=-1",
+		"/v1/search?address=Irvine&limit=all",
+		"/v1/stores",
+		"/v1/stores?address=Irvine&radius=-5",
+		"/v1/stores/osm-node-1/menu-check",
+		"/v1/stores/12345678901/menu-check",
+	}
+	for _, path := range paths {
+		var body map[string]string
+		if status := get(t, api, path, &body); status != http.StatusBadRequest || body["error"] == "" {
+			t.Errorf("%s: %d %v, want 400 with an error", path, status, body)
+		}
+	}
+}
+
+func TestServeUpstreamFailure(t *testing.T) {
+	api := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	for _, path := range []string{"/v1/search?address=Irvine", "/v1/stores?address=Irvine"} {
+		var body map[string]string
+		if status := get(t, api, path, &body); status != http.StatusBadGateway || body["error"] == "" {
+			t.Errorf("%s: %d %v, want 502 with an error", path, status, body)
+		}
+	}
+}
+
+func TestServeSearch(t *testing.T) {
+	api := newTestServer(t, fixtureUpstream(t))
+
+	var report searchReport
+	if status := get(t, api, "/v1/search?address=1+Glen+Bell+Way&radius=50000&limit=0", &report); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if report.SchemaVersion != schemaVersion || report.Item != "Chili Cheese Burrito" || report.Address != "1 Glen Bell Way" ||
+		report.RadiusMeters != 50000 || report.Interrupted {
+		t.Errorf("report = %+v", report)
+	}
+	want := map[string]string{"031447": "not_found", "018678": "found", "004012": "found"}
+	if len(report.Stores) != len(want) {
+		t.Fatalf("got %d stores, want %d", len(report.Stores), len(want))
+	}
+	for _, store := range report.Stores {
+		if store.Status != want[store.StoreID] {
+			t.Errorf("store %s: status %q, want %q", store.StoreID, store.Status, want[store.StoreID])
+		}
+	}
+
+	// The document's field names are part of the API
+	var raw map[string]any
+	get(t, api, "/v1/search?address=1+Glen+Bell+Way", &raw)
+	for _, key := range []string{"schema_version", "item", "address", "radius_m", "interrupted", "stores"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("search response has no %q", key)
+		}
+	}
+}
+
+func TestServeStoresAndMenuCheck(t *testing.T) {
+	api := newTestServer(t, fixtureUpstream(t))
+
+	var stores struct {
+		Stores []locationRecord `json:"stores"`
+	}
+	if status := get(t, api, "/v1/stores?address=1+Glen+Bell+Way", &stores); status != http.StatusOK {
+		t.Fatalf("stores: status %d", status)
+	}
+	if len(stores.Stores) != 3 || stores.Stores[0].StoreID == "" || stores.Stores[0].Source != "tacobell" {
+		t.Errorf("stores = %+v, want the three fixture stores", stores.Stores)
+	}
+
+	var record storeRecord
+	if status := get(t, api, "/v1/stores/004012/menu-check", &record); status != http.StatusOK {
+		t.Fatalf("menu-check: status %d", status)
+	}
+	if record.StoreID != "004012" || record.Status != "found" || record.Checker != "scrape" {
+		t.Errorf("menu-check = %+v, want found by scrape", record)
+	}
+}
@@ -0,0 +1,153 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// priceSelectors match the element holding a product's price in HTML menus
+const priceSelectors = ".product-price, .price, [data-price]"
+
+// pricePattern finds a dollar amount in a product card's text
+var pricePattern = regexp.MustCompile(`\$\s*(\d+(?:\.\d{1,2})?)`)
+
+// FetchMenu returns every item listed on a store's menu pages, in page
+// order and without duplicates. Items come from the pages' embedded menu
+// data when present and from their product listings otherwise. An item
+// listed on several pages is unavailable if any of them says so. Pages
+// that cannot be loaded are skipped; an error is returned only if none can.
+func (c *ScrapingMenuChecker) FetchMenu(ctx context.Context, storeID string) ([]MenuItem, error) {
+	logger := loggerFrom(ctx).With("store_id", storeID, "checker", c.Name())
+	baseURL := stringOr(c.BaseURL, DefaultTacoBellURL)
+
+	var items []MenuItem
+	// seen maps names and product codes to the item's index in items
+	seen := make(map[string]int)
+	loaded := 0
+	for _, path := range orDefault(c.Paths, DefaultMenuPaths) {
+		menuURL := fmt.Sprintf("%s%s?store=%s", baseURL, path, storeID)
+
+		htmlContent, err := c.fetch(ctx, menuURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			logger.Warn("failed to load menu page", "url", menuURL, "error", err)
+			continue
+		}
+		loaded++
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+		if err != nil {
+			logger.Warn("failed to parse menu page", "url", menuURL, "error", err)
+			continue
+		}
+		pageItems, ok := ParseMenuData(doc)
+		if !ok {
+			pageItems = c.listedItems(doc, path)
+		}
+		logger.Debug("read menu page", "url", menuURL, "items", len(pageItems), "menu_data", ok)
+
+		// The same item is often listed on the full menu and its category page
+		for _, item := range pageItems {
+			name := strings.ToLower(item.Name)
+			i, ok := seen[name]
+			if !ok && item.Code != "" {
+				i, ok = seen["code:"+item.Code]
+			}
+			if ok {
+				mergeMenuItem(&items[i], item)
+				seen[name] = i
+				if item.Code != "" {
+					seen["code:"+item.Code] = i
+				}
+				continue
+			}
+			seen[name] = len(items)
+			if item.Code != "" {
+				seen["code:"+item.Code] = len(items)
+			}
+			items = append(items, item)
+		}
+	}
+
+	if loaded == 0 {
+		return nil, fmt.Errorf("could not load any menu page for store %s", storeID)
+	}
+	return items, nil
+}
+
+// mergeMenuItem adds what a second listing of an item says about it. One
+// page may be stale, so the item stays unavailable if either says so.
+func mergeMenuItem(item *MenuItem, other MenuItem) {
+	item.Available = item.Available && other.Available
+	if item.Code == "" {
+		item.Code = other.Code
+	}
+	if item.Price == 0 {
+		item.Price = other.Price
+	}
+}
+
+// listedItems reads the products listed in a page's menu region. Their
+// category is the region's heading, or the page's name if it has none.
+func (c *ScrapingMenuChecker) listedItems(doc *goquery.Document, path string) []MenuItem {
+	region := menuRegion(doc, orDefault(c.Regions, DefaultMenuRegionSelectors), orDefault(c.Chrome, DefaultChromeSelectors))
+	category := strings.Join(strings.Fields(region.Find("h1").First().Text()), " ")
+	if category == "" {
+		category = pageName(path)
+	}
+
+	phrases := orDefault(c.UnavailablePhrases, DefaultUnavailablePhrases)
+	selectors := orDefault(c.UnavailableSelectors, DefaultUnavailableSelectors)
+	var entries []menuEntry
+	region.Find(strings.Join(orDefault(c.Selectors, DefaultMenuSelectors), ", ")).Each(func(i int, s *goquery.Selection) {
+		entries = append(entries, menuEntry{name: strings.TrimSpace(s.Text()), elem: s})
+	})
+
+	var items []MenuItem
+	for i, entry := range entries {
+		// Some selectors match whole product cards; the name is then
+		// their heading, if any
+		s := entry.elem
+		nameElem := s
+		if heading := s.Find("h2, h3, h4").First(); heading.Length() > 0 {
+			nameElem = heading
+		}
+		name := strings.Join(strings.Fields(nameElem.Text()), " ")
+		if name == "" {
+			continue
+		}
+
+		card := productCard(entries, i, phrases)
+		item := MenuItem{
+			Name:      name,
+			Code:      card.AttrOr("data-product-code", ""),
+			Category:  category,
+			Available: unavailableSignal(card, phrases, selectors) == "",
+		}
+		priceText := card.Find(priceSelectors).First().Text()
+		if priceText == "" {
+			priceText = card.Text()
+		}
+		if m := pricePattern.FindStringSubmatch(priceText); m != nil {
+			item.Price, _ = parsePrice(m[1])
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// pageName turns a menu path such as "/food/burritos" into "Burritos"
+func pageName(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+	name = strings.ReplaceAll(name, "-", " ")
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,59 @@
+package finder
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFetchMenu(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/menu", fixture(t, "menu/burritos_next_data.html"))
+	u.handle("GET /food/burritos", fixture(t, "menu/burritos_disabled.html"))
+	u.handle("GET /food/specialties", fixture(t, "menu/specialties_selector.html"))
+	// /food/specialty is not found and skipped
+
+	items, err := newTestFinder(u).FetchMenuContext(context.Background(), "031447")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MenuItem{
+		{Name: "Bean Burrito", Code: "22001", Category: "Burritos", Price: 1.99, Available: true},
+		// The menu data lists it but the burritos page has it disabled
+		{Name: "Chili Cheese Burrito", Code: "22948", Category: "Burritos", Price: 2.49, Available: false},
+		{Name: "Burrito Supreme®", Code: "22120", Category: "Burritos", Price: 4.99, Available: true},
+		{Name: "Mexican Pizza", Category: "Specialties", Available: true},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("FetchMenu =\n%+v\nwant\n%+v", items, want)
+	}
+}
+
+func TestFetchMenuListedItems(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /food/burritos", fixture(t, "menu/burritos_disabled.html"))
+
+	scraper := &ScrapingMenuChecker{BaseURL: u.URL, Paths: []string{"/food/burritos"}}
+	items, err := scraper.FetchMenu(context.Background(), "031447")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MenuItem{
+		{Name: "Bean Burrito", Category: "Burritos", Price: 1.99, Available: true},
+		{Name: "Chili Cheese Burrito", Category: "Burritos", Price: 2.49, Available: false},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("FetchMenu =\n%+v\nwant\n%+v", items, want)
+	}
+}
+
+func TestFetchMenuErrors(t *testing.T) {
+	u := newUpstream(t)
+	f := newTestFinder(u, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if _, err := f.FetchMenu("osm-node-1"); err == nil {
+		t.Error("FetchMenu accepted a placeholder store ID")
+	}
+	if _, err := f.FetchMenu("031447"); err == nil {
+		t.Error("FetchMenu succeeded without any menu page")
+	}
+}
@@ -0,0 +1,141 @@
+package finder
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeouts bound the finder's HTTP requests
+type Timeouts struct {
+	// Request bounds a whole request, including reading the body
+	Request time.Duration
+	// Dial bounds establishing a TCP connection
+	Dial time.Duration
+	// TLSHandshake bounds the TLS handshake
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the wait for response headers once the request
+	// has been sent
+	ResponseHeader time.Duration
+	// IdleConn is how long an idle keep-alive connection stays open
+	IdleConn time.Duration
+}
+
+// DefaultTimeouts are used unless WithTimeouts says otherwise
+var DefaultTimeouts = Timeouts{
+	Request:        20 * time.Second,
+	Dial:           10 * time.Second,
+	TLSHandshake:   10 * time.Second,
+	ResponseHeader: 15 * time.Second,
+	IdleConn:       90 * time.Second,
+}
+
+// withDefaults fills zero fields from DefaultTimeouts
+func (t Timeouts) withDefaults() Timeouts {
+	pick := func(v, def time.Duration) time.Duration {
+		if v == 0 {
+			return def
+		}
+		return v
+	}
+	return Timeouts{
+		Request:        pick(t.Request, DefaultTimeouts.Request),
+		Dial:           pick(t.Dial, DefaultTimeouts.Dial),
+		TLSHandshake:   pick(t.TLSHandshake, DefaultTimeouts.TLSHandshake),
+		ResponseHeader: pick(t.ResponseHeader, DefaultTimeouts.ResponseHeader),
+		IdleConn:       pick(t.IdleConn, DefaultTimeouts.IdleConn),
+	}
+}
+
+// maxIdleConnsPerHost keeps enough warm connections for parallel menu checks
+// against the same host
+const maxIdleConnsPerHost = 16
+
+// newTransport returns a transport tuned for many requests to a handful of
+// hosts
+func newTransport(timeouts Timeouts) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   timeouts.Dial,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       timeouts.IdleConn,
+		TLSHandshakeTimeout:   timeouts.TLSHandshake,
+		ResponseHeaderTimeout: timeouts.ResponseHeader,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// sharedTransport is the connection pool shared by every finder that uses
+// the default timeouts, so short-lived finders still reuse connections
+var sharedTransport = sync.OnceValue(func() http.RoundTripper {
+	return newTransport(DefaultTimeouts)
+})
+
+// hostLimitTransport caps the number of concurrent requests to each host.
+// A request holds its slot until its response body is closed.
+type hostLimitTransport struct {
+	base  http.RoundTripper
+	limit int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// newHostLimitTransport wraps base so that at most limit requests to the same
+// host are in flight at once. A limit below 1 disables the cap.
+func newHostLimitTransport(base http.RoundTripper, limit int) http.RoundTripper {
+	if limit < 1 {
+		return base
+	}
+	return &hostLimitTransport{base: base, limit: limit, slots: make(map[string]chan struct{})}
+}
+
+// slot returns the semaphore for host
+func (t *hostLimitTransport) slot(host string) chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sem, ok := t.slots[host]
+	if !ok {
+		sem = make(chan struct{}, t.limit)
+		t.slots[host] = sem
+	}
+	return sem
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *hostLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sem := t.slot(req.URL.Host)
+	select {
+	case sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		<-sem
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-sem }}
+	return resp, nil
+}
+
+// releasingBody calls release once when the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,99 @@
+package finder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport counts the requests it forwards
+type countingTransport struct {
+	base http.RoundTripper
+	n    atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n.Add(1)
+	return t.base.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /location/v1/{address}", fixture(t, "tacobell_geocode.json"))
+	u.handle("GET /tacobellwebservices/v4/tacobell/stores", fixture(t, "tacobell_stores.json"))
+	u.handle("GET /food/{page}", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			http.Error(w, "no session", http.StatusForbidden)
+			return
+		}
+		fixture(t, "menu/burritos.html")(w, r)
+	})
+
+	jar, _ := cookiejar.New(nil)
+	base, _ := url.Parse(u.URL)
+	jar.SetCookies(base, []*http.Cookie{{Name: "session", Value: "abc"}})
+	transport := &countingTransport{base: http.DefaultTransport}
+
+	f := newTestFinder(u, WithHTTPClient(&http.Client{Transport: transport, Jar: jar}))
+	results, err := f.FindChilitoBurritos("1 Glen Bell Way, Irvine, CA", 100000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Status == StatusError {
+			t.Errorf("store %s: %v", result.Location.StoreID, result.Err)
+		}
+	}
+	if int(transport.n.Load()) != u.count("") {
+		t.Errorf("%d of %d requests went through the client's transport", transport.n.Load(), u.count(""))
+	}
+	if f.client.Timeout != DefaultTimeouts.Request {
+		t.Errorf("Timeout = %v, want the default", f.client.Timeout)
+	}
+}
+
+func TestWithTimeouts(t *testing.T) {
+	u := newUpstream(t)
+	u.handle("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	f := newTestFinder(u, WithTimeouts(Timeouts{Request: 50 * time.Millisecond}), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if f.client.Timeout != 50*time.Millisecond {
+		t.Fatalf("Timeout = %v, want 50ms", f.client.Timeout)
+	}
+	_, err := f.client.Get(u.URL + "/slow")
+	var netErr interface{ Timeout() bool }
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+}
+
+func TestFindersShareConnectionPool(t *testing.T) {
+	a := NewChilitoBurritoFinder()
+	b := NewChilitoBurritoFinder()
+	if baseTransport(t, a) != baseTransport(t, b) {
+		t.Error("finders with default timeouts use separate transports")
+	}
+	c := NewChilitoBurritoFinder(WithTimeouts(Timeouts{Dial: time.Second}))
+	if baseTransport(t, c) == baseTransport(t, a) {
+		t.Error("custom timeouts changed the shared transport")
+	}
+}
+
+// baseTransport digs the innermost transport out of a finder's client
+func baseTransport(t *testing.T, f *ChilitoBurritoFinder) http.RoundTripper {
+	t.Helper()
+	rt := f.client.Transport.(*retryTransport).base
+	if limited, ok := rt.(*hostLimitTransport); ok {
+		rt = limited.base
+	}
+	return rt.(*rateLimitTransport).base
+}
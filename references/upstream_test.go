@@ -0,0 +1,112 @@
+package finder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// upstream is a local stand-in for every service the finder talks to. Each
+// route replays a recorded response from testdata.
+type upstream struct {
+	*httptest.Server
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newUpstream starts an empty stand-in; add routes with handle
+func newUpstream(t *testing.T) *upstream {
+	t.Helper()
+	u := &upstream{mux: http.NewServeMux()}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		u.requests = append(u.requests, r.URL.Path)
+		u.mu.Unlock()
+		u.mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+// handle registers a handler for a ServeMux pattern such as
+// "GET /location/v1/{address}"
+func (u *upstream) handle(pattern string, handler http.HandlerFunc) {
+	u.mux.HandleFunc(pattern, handler)
+}
+
+// endpoints points every built-in provider at the stand-in
+func (u *upstream) endpoints() Endpoints {
+	return Endpoints{
+		TacoBell:    u.URL,
+		TacoBellAPI: u.URL,
+		Nominatim:   u.URL + "/search",
+		Overpass:    u.URL + "/api/interpreter",
+		Mapbox:      u.URL,
+	}
+}
+
+// count returns how many requests were made for paths starting with prefix
+func (u *upstream) count(prefix string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	n := 0
+	for _, path := range u.requests {
+		if strings.HasPrefix(path, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// fixture replies with the contents of testdata/name
+func fixture(t *testing.T, name string) http.HandlerFunc {
+	return fixtureStatus(t, http.StatusOK, name)
+}
+
+// fixtureStatus replies with the given status and the contents of
+// testdata/name
+func fixtureStatus(t *testing.T, status int, name string) http.HandlerFunc {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentType := "text/html; charset=utf-8"
+	if filepath.Ext(name) == ".json" {
+		contentType = "application/json"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+}
+
+// storesByLatitude replays the store API fixture recorded around the
+// requested latitude
+func storesByLatitude(t *testing.T, fixtures map[string]string) http.HandlerFunc {
+	t.Helper()
+	handlers := make(map[string]http.HandlerFunc, len(fixtures))
+	for lat, name := range fixtures {
+		handlers[lat] = fixture(t, name)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.URL.Query().Get("latitude")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// newTestFinder creates a finder that only talks to u
+func newTestFinder(u *upstream, opts ...Option) *ChilitoBurritoFinder {
+	return NewChilitoBurritoFinder(append([]Option{WithEndpoints(u.endpoints())}, opts...)...)
+}